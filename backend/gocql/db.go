@@ -2,6 +2,8 @@ package gocql
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/gocql/gocql"
 
@@ -10,10 +12,20 @@ import (
 
 type DB struct {
 	sess *gocql.Session
+	pvo  *ProtoVersionObserver
+	ht   *HostTracker
+
+	inflight inflight
 }
 
-func NewDB(sess *gocql.Session) *DB {
-	return &DB{sess: sess}
+func NewDB(sess *gocql.Session, opts ...Option) *DB {
+	db := DB{sess: sess}
+
+	for _, opt := range opts {
+		opt(&db)
+	}
+
+	return &db
 }
 
 func trimValues(vs []interface{}) ([]interface{}, []func(*gocql.Query)) {
@@ -29,8 +41,25 @@ func trimValues(vs []interface{}) ([]interface{}, []func(*gocql.Query)) {
 				fns,
 				func(q *gocql.Query) { q.SetConsistency(gocql.Consistency(vv)) },
 			)
+		case cql.WithSerialConsistency:
+			fns = append(
+				fns,
+				func(q *gocql.Query) {
+					q.SerialConsistency(gocql.SerialConsistency(vv))
+				},
+			)
+		case cql.WithIdempotency:
+			fns = append(fns, func(q *gocql.Query) { q.Idempotent(bool(vv)) })
+		case cql.WithPageSize:
+			fns = append(fns, func(q *gocql.Query) { q.PageSize(int(vv)) })
+		case cql.WithPageState:
+			fns = append(fns, func(q *gocql.Query) { q.PageState([]byte(vv)) })
 		case cql.Option:
 		default:
+			if vv == cql.UnsetValue {
+				vv = gocql.UnsetValue
+			}
+
 			args = append(args, vv)
 		}
 	}
@@ -38,9 +67,53 @@ func trimValues(vs []interface{}) ([]interface{}, []func(*gocql.Query)) {
 	return args, fns
 }
 
+func queryTimeout(vs []interface{}) time.Duration {
+	var t time.Duration
+
+	for _, v := range vs {
+		if vv, ok := v.(cql.WithTimeout); ok {
+			t = time.Duration(vv)
+		}
+	}
+
+	return t
+}
+
+func withTimeout(ctx context.Context, t time.Duration) (context.Context, context.CancelFunc) {
+	if t <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, t)
+}
+
 func (db *DB) Session() *gocql.Session { return db.sess }
 
-func (db *DB) query(ctx context.Context, stmt string, vs []interface{}) *gocql.Query {
+// begin registers an operation as in flight, the returned function has to be
+// called once the operation is over to release it and its timeout context.
+func (db *DB) begin(ctx context.Context, t time.Duration) (context.Context, func(), error) {
+	release, ok := db.inflight.acquire()
+
+	if !ok {
+		return nil, nil, cql.ErrClosed
+	}
+
+	tctx, cancel := withTimeout(ctx, t)
+
+	return tctx, func() { cancel(); release() }, nil
+}
+
+// query builds the gocql query, the options attached to the context are
+// applied before the ones given along the values.
+func (db *DB) query(ctx context.Context, stmt string, vs []interface{}) (*gocql.Query, func(), error) {
+	vs = cql.WithContextOptions(ctx, vs)
+
+	ctx, done, err := db.begin(ctx, queryTimeout(vs))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var (
 		vvs, fns = trimValues(vs)
 		q        = db.sess.Query(stmt, vvs...).WithContext(ctx)
@@ -50,59 +123,268 @@ func (db *DB) query(ctx context.Context, stmt string, vs []interface{}) *gocql.Q
 		fn(q)
 	}
 
-	return q
+	return q, done, nil
 }
 
 func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
-	return db.query(ctx, stmt, vs).Exec()
+	q, done, err := db.query(ctx, stmt, vs)
+
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	return wrapError(q.Exec())
+}
+
+// casScanner and scanner only build and run their query once scanned, so an
+// operation is not registered as in flight until then.
+type casScanner struct {
+	db   *DB
+	ctx  context.Context
+	stmt string
+	vs   []interface{}
+}
+
+func (cs casScanner) ScanCAS(vs ...interface{}) (bool, error) {
+	q, done, err := cs.db.query(cs.ctx, cs.stmt, cs.vs)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer done()
+
+	ok, err := q.ScanCAS(vs...)
+
+	return ok, wrapError(err)
 }
 
 func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
-	return db.query(ctx, stmt, vs)
+	return casScanner{db: db, ctx: ctx, stmt: stmt, vs: vs}
 }
 
 func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
-	return scanner{db.query(ctx, stmt, vs)}
+	return &scanner{db: db, ctx: ctx, stmt: stmt, vs: vs}
+}
+
+func columnInfos(cs []gocql.ColumnInfo) []cql.ColumnInfo {
+	var cis = make([]cql.ColumnInfo, len(cs))
+
+	for i, c := range cs {
+		cis[i] = cql.ColumnInfo{
+			Keyspace: c.Keyspace,
+			Table:    c.Table,
+			Name:     c.Name,
+		}
+
+		if s, ok := c.TypeInfo.(fmt.Stringer); ok {
+			cis[i].Type = s.String()
+		}
+	}
+
+	return cis
 }
 
 type scanner struct {
-	cql.Scanner
+	db   *DB
+	ctx  context.Context
+	stmt string
+	vs   []interface{}
+
+	iter *gocql.Iter
+	err  error
+}
+
+// fetch runs the query, the operation is over once its first page is
+// fetched.
+func (s *scanner) fetch() (*gocql.Iter, error) {
+	if s.iter != nil || s.err != nil {
+		return s.iter, s.err
+	}
+
+	q, done, err := s.db.query(s.ctx, s.stmt, s.vs)
+
+	if err != nil {
+		s.err = err
+		return nil, err
+	}
+
+	defer done()
+
+	s.iter = q.Iter()
+
+	return s.iter, nil
+}
+
+// Columns executes the query if it was not already the case, the row is
+// then retrieved by the following call to Scan or MapScan.
+func (s *scanner) Columns() []cql.ColumnInfo {
+	iter, err := s.fetch()
+
+	if err != nil {
+		return nil
+	}
+
+	return columnInfos(iter.Columns())
 }
 
-func (s scanner) Scan(vs ...interface{}) error {
-	if err := s.Scanner.Scan(vs...); err != gocql.ErrNotFound {
+func (s *scanner) scan(fn func(*gocql.Iter)) error {
+	iter, err := s.fetch()
+
+	if err != nil {
 		return err
 	}
 
-	return cql.ErrNoRows
+	if iter.NumRows() == 0 {
+		if err := iter.Close(); err != nil {
+			return wrapError(err)
+		}
+
+		return cql.ErrNoRows
+	}
+
+	fn(iter)
+
+	return wrapError(iter.Close())
+}
+
+func (s *scanner) Scan(vs ...interface{}) error {
+	return s.scan(func(iter *gocql.Iter) { iter.Scan(vs...) })
+}
+
+func (s *scanner) MapScan(m map[string]interface{}) error {
+	return s.scan(func(iter *gocql.Iter) { iter.MapScan(m) })
 }
 
 type cursor struct {
 	*gocql.Iter
+
+	paged bool
+	done  func()
 }
 
+func (c cursor) Scan(vs ...interface{}) bool {
+	if c.paged && c.WillSwitchPage() {
+		return false
+	}
+
+	return c.Iter.Scan(vs...)
+}
+
+func (c cursor) MapScan(m map[string]interface{}) bool {
+	if c.paged && c.WillSwitchPage() {
+		return false
+	}
+
+	return c.Iter.MapScan(m)
+}
+
+func (c cursor) Columns() []cql.ColumnInfo {
+	return columnInfos(c.Iter.Columns())
+}
+
+func (c cursor) Close() error {
+	defer c.done()
+
+	return wrapError(c.Iter.Close())
+}
+
+func isPaged(vs []interface{}) bool {
+	for _, v := range vs {
+		switch v.(type) {
+		case cql.WithPageSize, cql.WithPageState:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Query returns a cursor iterating over every page of the result set unless
+// the query is given a cql.WithPageSize or a cql.WithPageState option. In that
+// case the cursor stops at the end of the fetched page and its PageState
+// method returns the state to provide to fetch the next one.
 func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
-	return cursor{db.query(ctx, stmt, vs).Iter()}
+	q, done, err := db.query(ctx, stmt, vs)
+
+	if err != nil {
+		return cql.ErrCursor(err)
+	}
+
+	paged := isPaged(cql.WithContextOptions(ctx, vs))
+
+	if paged {
+		q.Prefetch(0)
+	}
+
+	return cursor{Iter: q.Iter(), paged: paged, done: done}
 }
 
 type batch struct {
 	*gocql.Batch
 
 	db *DB
+
+	ctx        context.Context
+	timeout    time.Duration
+	idempotent bool
+}
+
+// Query converts the values of the statement the same way as the ones of a
+// single query, its options are discarded.
+func (b batch) Query(stmt string, vs ...interface{}) {
+	args, _ := trimValues(vs)
+
+	b.Batch.Query(stmt, args...)
+}
+
+func (b batch) build() (*gocql.Batch, func(), error) {
+	ctx, done, err := b.db.begin(b.ctx, b.timeout)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gb := b.Batch.WithContext(ctx)
+
+	if b.idempotent {
+		for i := range gb.Entries {
+			gb.Entries[i].Idempotent = true
+		}
+	}
+
+	return gb, done, nil
 }
 
 func (b batch) Exec() error {
-	return b.db.sess.ExecuteBatch(b.Batch)
+	gb, done, err := b.build()
+
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	return wrapError(b.db.sess.ExecuteBatch(gb))
 }
 
 func (b batch) ExecCAS() (bool, cql.Cursor, error) {
-	ok, iter, err := b.db.sess.ExecuteBatchCAS(b.Batch)
+	gb, done, err := b.build()
+
+	if err != nil {
+		return false, nil, err
+	}
+
+	ok, iter, err := b.db.sess.ExecuteBatchCAS(gb)
 
 	if err != nil {
-		return ok, nil, err
+		done()
+		return ok, nil, wrapError(err)
 	}
 
-	return ok, cursor{iter}, nil
+	return ok, cursor{Iter: iter, done: done}, nil
 }
 
 var gocqlBatchTypes = map[cql.BatchType]gocql.BatchType{
@@ -112,23 +394,44 @@ var gocqlBatchTypes = map[cql.BatchType]gocql.BatchType{
 }
 
 func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
-	b := db.sess.NewBatch(gocqlBatchTypes[bt]).WithContext(ctx)
+	b := batch{Batch: db.sess.NewBatch(gocqlBatchTypes[bt]), db: db, ctx: ctx}
 
-	for _, o := range opts {
-		if c, ok := o.(cql.WithConsistency); ok {
-			b.SetConsistency(gocql.Consistency(c))
+	for _, o := range append(cql.ContextOptions(ctx), opts...) {
+		switch oo := o.(type) {
+		case cql.WithConsistency:
+			b.SetConsistency(gocql.Consistency(oo))
+		case cql.WithSerialConsistency:
+			b.SerialConsistency(gocql.SerialConsistency(oo))
+		case cql.WithIdempotency:
+			b.idempotent = bool(oo)
+		case cql.WithTimeout:
+			b.timeout = time.Duration(oo)
 		}
 	}
 
-	return batch{Batch: b, db: db}
+	return b
 }
 
-func GetSession(db cql.DB) *gocql.Session {
-	if u, ok := db.(interface{ Unwrap() cql.DB }); ok {
-		return GetSession(u.Unwrap())
+// Close stops accepting new operations, waits for the in flight ones to be
+// over or for the context to be done and closes the underlying session.
+func (db *DB) Close(ctx context.Context) error {
+	var err error
+
+	select {
+	case <-db.inflight.drain():
+	case <-ctx.Done():
+		err = ctx.Err()
 	}
 
-	if gdb, ok := db.(*DB); ok {
+	db.sess.Close()
+
+	return err
+}
+
+func GetSession(db cql.DB) *gocql.Session {
+	var gdb *DB
+
+	if cql.As(db, &gdb) {
 		return gdb.sess
 	}
 
@@ -0,0 +1,24 @@
+package gocql
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/cql"
+)
+
+func TestBatchQuery(t *testing.T) {
+	b := batch{Batch: gocql.NewBatch(gocql.LoggedBatch)}
+
+	b.Query(
+		"INSERT INTO users(id, name) VALUES (?, ?)",
+		1,
+		cql.UnsetValue,
+		cql.WithConsistency(cql.One),
+	)
+
+	assert.Len(t, b.Entries, 1)
+	assert.Equal(t, []interface{}{1, gocql.UnsetValue}, b.Entries[0].Args)
+}
@@ -0,0 +1,34 @@
+package gocql
+
+import (
+	"github.com/gocql/gocql"
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+var errorClasses = map[int]error{
+	0x0100: cql.ErrUnauthorized,
+	0x1000: cql.ErrUnavailable,
+	0x1001: cql.ErrOverloaded,
+	0x1100: cql.ErrWriteTimeout,
+	0x1200: cql.ErrReadTimeout,
+	0x2000: cql.ErrInvalidQuery,
+	0x2100: cql.ErrUnauthorized,
+	0x2200: cql.ErrInvalidQuery,
+	0x2400: cql.ErrAlreadyExists,
+}
+
+func wrapError(err error) error {
+	var rerr gocql.RequestError
+
+	if !errors.As(err, &rerr) {
+		return err
+	}
+
+	if class, ok := errorClasses[rerr.Code()]; ok {
+		return &cql.Error{Class: class, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,53 @@
+package gocql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+type requestError struct{ code int }
+
+func (re requestError) Code() int       { return re.code }
+func (re requestError) Message() string { return "request error" }
+func (re requestError) Error() string   { return re.Message() }
+
+func TestWrapError(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+
+		wantClass error
+	}{
+		{name: "nil"},
+		{name: "unknown error", err: errors.New("foo")},
+		{name: "unmapped code", err: requestError{code: 0x0000}},
+		{
+			name:      "read timeout",
+			err:       requestError{code: 0x1200},
+			wantClass: cql.ErrReadTimeout,
+		},
+		{
+			name:      "wrapped unavailable",
+			err:       errors.Wrap(requestError{code: 0x1000}, "wrapped"),
+			wantClass: cql.ErrUnavailable,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapError(tt.err)
+
+			assert.Equal(t, tt.wantClass, cql.ErrorClass(err))
+
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err))
+			}
+
+			if tt.wantClass != nil {
+				assert.True(t, errors.Is(err, tt.wantClass))
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package gocql
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gocql/gocql"
+)
+
+// HostTracker wraps a host selection policy to keep track of the hosts known
+// by the session, Ping reports their status.
+type HostTracker struct {
+	gocql.HostSelectionPolicy
+
+	mu    sync.RWMutex
+	hosts map[string]*gocql.HostInfo
+}
+
+func NewHostTracker(p gocql.HostSelectionPolicy) *HostTracker {
+	if p == nil {
+		p = gocql.RoundRobinHostPolicy()
+	}
+
+	return &HostTracker{
+		HostSelectionPolicy: p,
+		hosts:               make(map[string]*gocql.HostInfo),
+	}
+}
+
+func (ht *HostTracker) AddHost(h *gocql.HostInfo) {
+	ht.mu.Lock()
+	ht.hosts[h.HostnameAndPort()] = h
+	ht.mu.Unlock()
+
+	ht.HostSelectionPolicy.AddHost(h)
+}
+
+func (ht *HostTracker) AddHosts(hs []*gocql.HostInfo) {
+	ht.mu.Lock()
+
+	for _, h := range hs {
+		ht.hosts[h.HostnameAndPort()] = h
+	}
+
+	ht.mu.Unlock()
+
+	if bp, ok := ht.HostSelectionPolicy.(interface {
+		AddHosts([]*gocql.HostInfo)
+	}); ok {
+		bp.AddHosts(hs)
+		return
+	}
+
+	for _, h := range hs {
+		ht.HostSelectionPolicy.AddHost(h)
+	}
+}
+
+func (ht *HostTracker) RemoveHost(h *gocql.HostInfo) {
+	ht.mu.Lock()
+	delete(ht.hosts, h.HostnameAndPort())
+	ht.mu.Unlock()
+
+	ht.HostSelectionPolicy.RemoveHost(h)
+}
+
+func (ht *HostTracker) Hosts() []*gocql.HostInfo {
+	ht.mu.RLock()
+
+	var (
+		ks = make([]string, 0, len(ht.hosts))
+		hs = make([]*gocql.HostInfo, 0, len(ht.hosts))
+	)
+
+	for k := range ht.hosts {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	for _, k := range ks {
+		hs = append(hs, ht.hosts[k])
+	}
+
+	ht.mu.RUnlock()
+
+	return hs
+}
+
+func WithHostTracker(ht *HostTracker) Option {
+	return func(db *DB) { db.ht = ht }
+}
@@ -0,0 +1,53 @@
+package gocql
+
+import "sync"
+
+type inflight struct {
+	mu sync.Mutex
+
+	count   int
+	closing bool
+	drained chan struct{}
+}
+
+func (i *inflight) acquire() (func(), bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.closing {
+		return nil, false
+	}
+
+	i.count++
+
+	var once sync.Once
+
+	return func() { once.Do(i.release) }, true
+}
+
+func (i *inflight) release() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.count--
+
+	if i.closing && i.count == 0 {
+		close(i.drained)
+	}
+}
+
+func (i *inflight) drain() <-chan struct{} {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !i.closing {
+		i.closing = true
+		i.drained = make(chan struct{})
+
+		if i.count == 0 {
+			close(i.drained)
+		}
+	}
+
+	return i.drained
+}
@@ -0,0 +1,66 @@
+package gocql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/cql"
+)
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestInflight(t *testing.T) {
+	var i inflight
+
+	r1, ok := i.acquire()
+	assert.True(t, ok)
+
+	r2, ok := i.acquire()
+	assert.True(t, ok)
+
+	r1()
+
+	drained := i.drain()
+
+	assert.False(t, isClosed(drained))
+
+	_, ok = i.acquire()
+	assert.False(t, ok)
+
+	r1()
+	assert.False(t, isClosed(drained))
+
+	r2()
+	assert.True(t, isClosed(drained))
+	assert.True(t, isClosed(i.drain()))
+}
+
+func TestInflightDrainIdle(t *testing.T) {
+	var i inflight
+
+	assert.True(t, isClosed(i.drain()))
+}
+
+func TestInflightScannerNotScanned(t *testing.T) {
+	var (
+		db  DB
+		ctx = context.Background()
+	)
+
+	db.QueryRow(ctx, "SELECT")
+	csc := db.ExecCAS(ctx, "UPDATE")
+
+	assert.True(t, isClosed(db.inflight.drain()))
+
+	_, err := csc.ScanCAS()
+	assert.ErrorIs(t, err, cql.ErrClosed)
+}
@@ -0,0 +1,61 @@
+package gocql
+
+import (
+	"context"
+	"time"
+
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+const (
+	localReleaseVersionStmt = "SELECT release_version FROM system.local"
+
+	// schemaAgreementTimeout bounds the wait for the schema versions of the
+	// nodes to agree, gocql would otherwise wait up to a minute.
+	schemaAgreementTimeout = time.Second
+)
+
+// Ping runs a cheap query against the system keyspace. The schema agreement
+// is checked by gocql from its control connection, which reads both the local
+// and the peers schema versions from the same node. It is reported as false
+// when the versions do not agree within schemaAgreementTimeout.
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	var (
+		h cql.Health
+
+		version string
+	)
+
+	if db.ht != nil {
+		for _, hi := range db.ht.Hosts() {
+			h.Hosts = append(
+				h.Hosts,
+				cql.HostStatus{
+					Address:    hi.HostnameAndPort(),
+					DataCenter: hi.DataCenter(),
+					Rack:       hi.Rack(),
+					Up:         hi.IsUp(),
+				},
+			)
+		}
+	}
+
+	if err := db.QueryRow(ctx, localReleaseVersionStmt).Scan(&version); err != nil {
+		return h, errors.Wrap(err, "cant query system.local")
+	}
+
+	actx, cancel := context.WithTimeout(ctx, schemaAgreementTimeout)
+	defer cancel()
+
+	err := db.sess.AwaitSchemaAgreement(actx)
+
+	if cerr := ctx.Err(); cerr != nil {
+		return h, cerr
+	}
+
+	h.SchemaAgreement = err == nil
+
+	return h, nil
+}
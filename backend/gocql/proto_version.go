@@ -0,0 +1,48 @@
+package gocql
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gocql/gocql"
+)
+
+// ProtoVersionObserver records the native protocol version of the frames
+// received by a session, which is the version negotiated with the cluster.
+type ProtoVersionObserver struct {
+	next gocql.FrameHeaderObserver
+
+	version int32
+}
+
+func NewProtoVersionObserver(next gocql.FrameHeaderObserver) *ProtoVersionObserver {
+	return &ProtoVersionObserver{next: next}
+}
+
+func (o *ProtoVersionObserver) ObserveFrameHeader(ctx context.Context, h gocql.ObservedFrameHeader) {
+	atomic.StoreInt32(&o.version, int32(byte(h.Version)&0x7f))
+
+	if o.next != nil {
+		o.next.ObserveFrameHeader(ctx, h)
+	}
+}
+
+func (o *ProtoVersionObserver) ProtoVersion() int {
+	return int(atomic.LoadInt32(&o.version))
+}
+
+type Option func(*DB)
+
+func WithProtoVersionObserver(o *ProtoVersionObserver) Option {
+	return func(db *DB) { db.pvo = o }
+}
+
+// ProtoVersion returns the native protocol version used by the session or 0
+// if the DB was not given a ProtoVersionObserver.
+func (db *DB) ProtoVersion() int {
+	if db.pvo == nil {
+		return 0
+	}
+
+	return db.pvo.ProtoVersion()
+}
@@ -0,0 +1,33 @@
+package gocql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+)
+
+type frameHeaderRecorder struct {
+	headers []gocql.ObservedFrameHeader
+}
+
+func (r *frameHeaderRecorder) ObserveFrameHeader(_ context.Context, h gocql.ObservedFrameHeader) {
+	r.headers = append(r.headers, h)
+}
+
+func TestProtoVersionObserver(t *testing.T) {
+	var (
+		r   frameHeaderRecorder
+		pvo = NewProtoVersionObserver(&r)
+	)
+
+	assert.Equal(t, 0, NewDB(nil).ProtoVersion())
+	assert.Equal(t, 0, pvo.ProtoVersion())
+
+	pvo.ObserveFrameHeader(context.Background(), gocql.ObservedFrameHeader{Version: 0x84})
+
+	assert.Equal(t, 4, pvo.ProtoVersion())
+	assert.Equal(t, 4, NewDB(nil, WithProtoVersionObserver(pvo)).ProtoVersion())
+	assert.Len(t, r.headers, 1)
+}
@@ -0,0 +1,87 @@
+package cql
+
+import "reflect"
+
+// Unwrapper is implemented by the middlewares, Unwrap returns the DB wrapped
+// by the middleware, in other words the next layer of the chain.
+type Unwrapper interface {
+	Unwrap() DB
+}
+
+// Unwrap returns the next layer of the chain or nil when db does not
+// implement Unwrapper.
+func Unwrap(db DB) DB {
+	if u, ok := db.(Unwrapper); ok {
+		return u.Unwrap()
+	}
+
+	return nil
+}
+
+// Layers lists every layer of the chain from the outermost to the innermost.
+func Layers(db DB) []DB {
+	var dbs []DB
+
+	for ; db != nil; db = Unwrap(db) {
+		dbs = append(dbs, db)
+	}
+
+	return dbs
+}
+
+// As finds the first layer of the chain assignable to the value pointed to by
+// target, if any it sets target to it and returns true. It panics if target
+// is not a non-nil pointer to a type implementing DB or to an interface.
+func As(db DB, target interface{}) bool {
+	if target == nil {
+		panic("cql: target cannot be nil")
+	}
+
+	v := reflect.ValueOf(target)
+	t := v.Type()
+
+	if t.Kind() != reflect.Ptr || v.IsNil() {
+		panic("cql: target must be a non-nil pointer")
+	}
+
+	et := t.Elem()
+
+	if et.Kind() != reflect.Interface && !et.Implements(reflect.TypeOf((*DB)(nil)).Elem()) {
+		panic("cql: *target must be an interface or implement DB")
+	}
+
+	for ; db != nil; db = Unwrap(db) {
+		if reflect.TypeOf(db).AssignableTo(et) {
+			v.Elem().Set(reflect.ValueOf(db))
+			return true
+		}
+	}
+
+	return false
+}
+
+type chain []MiddlewareFactory
+
+func (c chain) Wrap(db DB) DB {
+	for _, f := range c {
+		db = f.Wrap(db)
+	}
+
+	return db
+}
+
+// Chain composes the factories into a single one, they are applied in order
+// so the last factory ends up being the outermost layer.
+func Chain(fs ...MiddlewareFactory) MiddlewareFactory {
+	var c chain
+
+	for _, f := range fs {
+		if cc, ok := f.(chain); ok {
+			c = append(c, cc...)
+		} else if f != nil {
+			c = append(c, f)
+		}
+	}
+
+	return c
+}
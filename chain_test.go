@@ -0,0 +1,78 @@
+package cql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDB struct{ DB }
+
+type wrapDB struct {
+	DB
+
+	name string
+}
+
+func (db *wrapDB) Unwrap() DB { return db.DB }
+
+type otherDB struct{ DB }
+
+func (db *otherDB) Unwrap() DB                  { return db.DB }
+func (db *otherDB) Close(context.Context) error { return nil }
+
+type wrapFactory string
+
+func (f wrapFactory) Wrap(db DB) DB { return &wrapDB{DB: db, name: string(f)} }
+
+type otherFactory struct{}
+
+func (otherFactory) Wrap(db DB) DB { return &otherDB{DB: db} }
+
+func TestChain(t *testing.T) {
+	var (
+		base = &mockDB{}
+		db   = Chain(
+			wrapFactory("inner"),
+			Chain(otherFactory{}, nil),
+			wrapFactory("outer"),
+		).Wrap(base)
+	)
+
+	layers := Layers(db)
+
+	assert.Len(t, layers, 4)
+	assert.Equal(t, "outer", layers[0].(*wrapDB).name)
+	assert.IsType(t, &otherDB{}, layers[1])
+	assert.Equal(t, "inner", layers[2].(*wrapDB).name)
+	assert.Equal(t, base, layers[3])
+	assert.Nil(t, Unwrap(base))
+
+	var w *wrapDB
+
+	assert.True(t, As(db, &w))
+	assert.Equal(t, "outer", w.name)
+
+	var o *otherDB
+
+	assert.True(t, As(db, &o))
+	assert.Equal(t, layers[1], o)
+
+	var m *mockDB
+
+	assert.True(t, As(db, &m))
+	assert.Equal(t, base, m)
+
+	var c Closer
+
+	assert.True(t, As(db, &c))
+	assert.Equal(t, layers[1], c)
+
+	var p Pinger
+
+	assert.False(t, As(db, &p))
+
+	assert.Panics(t, func() { As(db, (*wrapDB)(nil)) })
+	assert.Panics(t, func() { As(db, &struct{}{}) })
+}
@@ -0,0 +1,73 @@
+package cql
+
+import (
+	"strings"
+
+	"github.com/upfluence/errors"
+)
+
+var (
+	ErrInvalidConsistency = errors.New("Invalid consistency")
+
+	consistencies       = []Consistency{Any, One, Two, Three, Quorum, All, LocalQuorum, EachQuorum, LocalOne}
+	serialConsistencies = []SerialConsistency{Serial, LocalSerial}
+)
+
+func normalizeLevel(s string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(s))
+}
+
+// ParseConsistency parses the consistency level as written in CQL
+// ("LOCAL_QUORUM"), in lower case ("local_quorum") or as the constant name
+// ("LocalQuorum").
+func ParseConsistency(s string) (Consistency, error) {
+	l := normalizeLevel(s)
+
+	for _, c := range consistencies {
+		if normalizeLevel(c.String()) == l {
+			return c, nil
+		}
+	}
+
+	return Any, errors.Wrapf(ErrInvalidConsistency, "%q", s)
+}
+
+func (c Consistency) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Consistency) UnmarshalText(b []byte) error {
+	cc, err := ParseConsistency(string(b))
+
+	if err == nil {
+		*c = cc
+	}
+
+	return err
+}
+
+func ParseSerialConsistency(s string) (SerialConsistency, error) {
+	l := normalizeLevel(s)
+
+	for _, c := range serialConsistencies {
+		if normalizeLevel(c.String()) == l {
+			return c, nil
+		}
+	}
+
+	return 0, errors.Wrapf(ErrInvalidConsistency, "%q", s)
+}
+
+func (c SerialConsistency) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *SerialConsistency) UnmarshalText(b []byte) error {
+	cc, err := ParseSerialConsistency(string(b))
+
+	if err == nil {
+		*c = cc
+	}
+
+	return err
+}
@@ -0,0 +1,120 @@
+package cql
+
+import "context"
+
+type optionsKey struct{}
+type tagsKey struct{}
+
+// ContextWithOptions attaches options to the context, the backends apply
+// them to every operation run with the context as if they were given before
+// the values of the operation. The options given at the call site win.
+func ContextWithOptions(ctx context.Context, opts ...Option) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+
+	prev := ContextOptions(ctx)
+
+	return context.WithValue(
+		ctx,
+		optionsKey{},
+		append(append(make([]Option, 0, len(prev)+len(opts)), prev...), opts...),
+	)
+}
+
+func ContextOptions(ctx context.Context) []Option {
+	opts, _ := ctx.Value(optionsKey{}).([]Option)
+
+	return opts
+}
+
+// WithContextOptions prepends the options attached to the context to the
+// values of an operation.
+func WithContextOptions(ctx context.Context, vs []interface{}) []interface{} {
+	opts := ContextOptions(ctx)
+
+	if len(opts) == 0 {
+		return vs
+	}
+
+	vvs := make([]interface{}, 0, len(opts)+len(vs))
+
+	for _, opt := range opts {
+		vvs = append(vvs, opt)
+	}
+
+	return append(vvs, vs...)
+}
+
+// LookupNamedQuery returns the last cql.NamedQuery of the values, the ones
+// given at the call site thus win over the ones merged from the context by
+// WithContextOptions.
+func LookupNamedQuery(vs []interface{}) (string, bool) {
+	for i := len(vs) - 1; i >= 0; i-- {
+		if nq, ok := vs[i].(NamedQuery); ok {
+			return string(nq), true
+		}
+	}
+
+	return "", false
+}
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// ContextWithTags attaches tags, given as key value pairs, to the context.
+// They are not sent to Cassandra but the middlewares use them to label the
+// operations. A tag already set is overridden.
+func ContextWithTags(ctx context.Context, kv ...string) context.Context {
+	if len(kv) == 0 {
+		return ctx
+	}
+
+	var (
+		prev = ContextTags(ctx)
+		tags = make([]Tag, len(prev), len(prev)+(len(kv)+1)/2)
+	)
+
+	copy(tags, prev)
+
+	for i := 0; i < len(kv); i += 2 {
+		t := Tag{Key: kv[i]}
+
+		if i+1 < len(kv) {
+			t.Value = kv[i+1]
+		}
+
+		tags = setTag(tags, t)
+	}
+
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+func setTag(tags []Tag, t Tag) []Tag {
+	for i, tt := range tags {
+		if tt.Key == t.Key {
+			tags[i] = t
+			return tags
+		}
+	}
+
+	return append(tags, t)
+}
+
+func ContextTags(ctx context.Context) []Tag {
+	tags, _ := ctx.Value(tagsKey{}).([]Tag)
+
+	return tags
+}
+
+func ContextTag(ctx context.Context, k string) (string, bool) {
+	for _, t := range ContextTags(ctx) {
+		if t.Key == k {
+			return t.Value, true
+		}
+	}
+
+	return "", false
+}
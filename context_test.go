@@ -0,0 +1,59 @@
+package cql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextOptions(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, []interface{}{1}, WithContextOptions(ctx, []interface{}{1}))
+
+	ctx = ContextWithOptions(ctx, WithConsistency(LocalOne))
+	ctx = ContextWithOptions(ctx, NamedQuery("foo"))
+
+	assert.Equal(
+		t,
+		[]interface{}{WithConsistency(LocalOne), NamedQuery("foo"), 1, WithConsistency(One)},
+		WithContextOptions(ctx, []interface{}{1, WithConsistency(One)}),
+	)
+}
+
+func TestLookupNamedQuery(t *testing.T) {
+	ctx := ContextWithOptions(context.Background(), NamedQuery("ctx"))
+
+	nq, ok := LookupNamedQuery(WithContextOptions(ctx, []interface{}{1}))
+	assert.True(t, ok)
+	assert.Equal(t, "ctx", nq)
+
+	nq, ok = LookupNamedQuery(
+		WithContextOptions(ctx, []interface{}{1, NamedQuery("call")}),
+	)
+	assert.True(t, ok)
+	assert.Equal(t, "call", nq)
+
+	_, ok = LookupNamedQuery([]interface{}{1})
+	assert.False(t, ok)
+}
+
+func TestContextTags(t *testing.T) {
+	var (
+		ctx    = ContextWithTags(context.Background(), "tenant", "x", "handler", "list")
+		child  = ContextWithTags(ctx, "tenant", "y", "dangling")
+		v, ok  = ContextTag(child, "tenant")
+		_, nok = ContextTag(child, "unknown")
+	)
+
+	assert.Equal(t, []Tag{{"tenant", "x"}, {"handler", "list"}}, ContextTags(ctx))
+	assert.Equal(
+		t,
+		[]Tag{{"tenant", "y"}, {"handler", "list"}, {"dangling", ""}},
+		ContextTags(child),
+	)
+	assert.True(t, ok)
+	assert.Equal(t, "y", v)
+	assert.False(t, nok)
+}
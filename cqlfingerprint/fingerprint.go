@@ -0,0 +1,136 @@
+package cqlfingerprint
+
+import (
+	"regexp"
+	"strings"
+)
+
+var inListRe = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+
+func isIdentChar(c byte) bool {
+	return c == '_' ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		('0' <= c && c <= '9')
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isHex(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func isUUID(s string) bool {
+	if len(s) < 36 || (len(s) > 36 && isIdentChar(s[36])) {
+		return false
+	}
+
+	for i := 0; i < 36; i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Fingerprint normalizes a statement so the statements only differing by
+// their literal values or the length of their IN lists share the same
+// fingerprint. String, numeric, blob and UUID literals are replaced by a
+// placeholder and the whitespaces are collapsed.
+func Fingerprint(stmt string) string {
+	var (
+		b     strings.Builder
+		space bool
+	)
+
+	b.Grow(len(stmt))
+
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			space = true
+			i++
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+
+		space = false
+
+		switch {
+		case c == '\'':
+			i++
+
+			for i < len(stmt) {
+				if stmt[i] == '\'' {
+					if i+1 < len(stmt) && stmt[i+1] == '\'' {
+						i += 2
+						continue
+					}
+
+					break
+				}
+
+				i++
+			}
+
+			b.WriteByte('?')
+			i++
+		case strings.HasPrefix(stmt[i:], "$$"):
+			if j := strings.Index(stmt[i+2:], "$$"); j >= 0 {
+				i += j + 4
+			} else {
+				i = len(stmt)
+			}
+
+			b.WriteByte('?')
+		case c == '"':
+			j := strings.IndexByte(stmt[i+1:], '"')
+
+			if j < 0 {
+				j = len(stmt) - i - 1
+			} else {
+				j++
+			}
+
+			b.WriteString(stmt[i : i+j+1])
+			i += j + 1
+		case isUUID(stmt[i:]):
+			b.WriteByte('?')
+			i += 36
+		case isDigit(c) || (c == '-' && i+1 < len(stmt) && isDigit(stmt[i+1])):
+			i++
+
+			for i < len(stmt) && (isIdentChar(stmt[i]) || stmt[i] == '.') {
+				i++
+			}
+
+			b.WriteByte('?')
+		case isIdentChar(c):
+			j := i
+
+			for j < len(stmt) && isIdentChar(stmt[j]) {
+				j++
+			}
+
+			b.WriteString(stmt[i:j])
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return inListRe.ReplaceAllString(b.String(), "IN (?)")
+}
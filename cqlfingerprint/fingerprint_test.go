@@ -0,0 +1,36 @@
+package cqlfingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			in:  "SELECT id FROM users WHERE id = ?",
+			out: "SELECT id FROM users WHERE id = ?",
+		},
+		{
+			in:  "SELECT  id\n\tFROM users  WHERE name = 'it''s' LIMIT 10",
+			out: "SELECT id FROM users WHERE name = ? LIMIT ?",
+		},
+		{
+			in:  "SELECT * FROM t1 WHERE id IN (1, 2, 3) AND k in (?,?)",
+			out: "SELECT * FROM t1 WHERE id IN (?) AND k IN (?)",
+		},
+		{
+			in:  "UPDATE t2 SET b = 0xCAFE, f = -1.5e3 WHERE id = 123e4567-e89b-12d3-a456-426614174000",
+			out: "UPDATE t2 SET b = ?, f = ? WHERE id = ?",
+		},
+		{
+			in:  `INSERT INTO "Users" (id, "Col 1", body) VALUES (?, ?, $$a 'b' c$$)`,
+			out: `INSERT INTO "Users" (id, "Col 1", body) VALUES (?, ?, ?)`,
+		},
+	} {
+		assert.Equal(t, tt.out, Fingerprint(tt.in))
+	}
+}
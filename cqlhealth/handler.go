@@ -0,0 +1,128 @@
+package cqlhealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/upfluence/cql"
+)
+
+const defaultTimeout = 5 * time.Second
+
+type Host struct {
+	Address    string `json:"address"`
+	DataCenter string `json:"datacenter,omitempty"`
+	Rack       string `json:"rack,omitempty"`
+	Up         bool   `json:"up"`
+}
+
+type Status struct {
+	Healthy         bool   `json:"healthy"`
+	SchemaAgreement bool   `json:"schema_agreement"`
+	Hosts           []Host `json:"hosts,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+type Option func(*handler)
+
+func WithTimeout(d time.Duration) Option {
+	return func(h *handler) { h.timeout = d }
+}
+
+type handler struct {
+	db      cql.DB
+	timeout time.Duration
+	healthy func(cql.Health, error) bool
+}
+
+func newHandler(db cql.DB, fn func(cql.Health, error) bool, opts []Option) *handler {
+	h := handler{db: db, timeout: defaultTimeout, healthy: fn}
+
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	return &h
+}
+
+// ReadinessHandler reports the DB as healthy when it answers the ping and at
+// least one of the hosts it knows about is up.
+func ReadinessHandler(db cql.DB, opts ...Option) http.Handler {
+	return newHandler(db, isReady, opts)
+}
+
+// LivenessHandler only reports the DB as unhealthy once it is closed, a
+// Cassandra outage should not get the process restarted.
+func LivenessHandler(db cql.DB, opts ...Option) http.Handler {
+	return newHandler(db, isAlive, opts)
+}
+
+func isReady(h cql.Health, err error) bool {
+	if err != nil {
+		return false
+	}
+
+	if len(h.Hosts) == 0 {
+		return true
+	}
+
+	for _, hs := range h.Hosts {
+		if hs.Up {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAlive(_ cql.Health, err error) bool {
+	return !errors.Is(err, cql.ErrClosed)
+}
+
+func (h *handler) status(ctx context.Context) Status {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	health, err := cql.Ping(ctx, h.db)
+
+	s := Status{
+		Healthy:         h.healthy(health, err),
+		SchemaAgreement: health.SchemaAgreement,
+	}
+
+	for _, hs := range health.Hosts {
+		s.Hosts = append(
+			s.Hosts,
+			Host{
+				Address:    hs.Address,
+				DataCenter: hs.DataCenter,
+				Rack:       hs.Rack,
+				Up:         hs.Up,
+			},
+		)
+	}
+
+	if err != nil {
+		s.Error = err.Error()
+	}
+
+	return s
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := h.status(r.Context())
+
+	code := http.StatusOK
+
+	if !s.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	json.NewEncoder(w).Encode(s)
+}
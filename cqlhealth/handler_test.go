@@ -0,0 +1,110 @@
+package cqlhealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/upfluence/cql"
+)
+
+type mockDB struct {
+	cql.DB
+
+	health cql.Health
+	err    error
+}
+
+func (db *mockDB) Ping(context.Context) (cql.Health, error) {
+	return db.health, db.err
+}
+
+func TestHandlers(t *testing.T) {
+	var (
+		up   = cql.HostStatus{Address: "10.0.0.1:9042", DataCenter: "dc1", Up: true}
+		down = cql.HostStatus{Address: "10.0.0.2:9042", DataCenter: "dc1"}
+	)
+
+	for _, tt := range []struct {
+		name string
+		db   *mockDB
+
+		wantReadiness int
+		wantLiveness  int
+		wantStatus    Status
+	}{
+		{
+			name: "healthy",
+			db: &mockDB{
+				health: cql.Health{
+					Hosts:           []cql.HostStatus{up, down},
+					SchemaAgreement: true,
+				},
+			},
+			wantReadiness: http.StatusOK,
+			wantLiveness:  http.StatusOK,
+			wantStatus: Status{
+				Healthy:         true,
+				SchemaAgreement: true,
+				Hosts: []Host{
+					{Address: "10.0.0.1:9042", DataCenter: "dc1", Up: true},
+					{Address: "10.0.0.2:9042", DataCenter: "dc1"},
+				},
+			},
+		},
+		{
+			name:          "all hosts down",
+			db:            &mockDB{health: cql.Health{Hosts: []cql.HostStatus{down}}},
+			wantReadiness: http.StatusServiceUnavailable,
+			wantLiveness:  http.StatusOK,
+			wantStatus: Status{
+				Hosts: []Host{{Address: "10.0.0.2:9042", DataCenter: "dc1"}},
+			},
+		},
+		{
+			name:          "ping failure",
+			db:            &mockDB{err: errors.New("unavailable")},
+			wantReadiness: http.StatusServiceUnavailable,
+			wantLiveness:  http.StatusOK,
+			wantStatus:    Status{Error: "unavailable"},
+		},
+		{
+			name:          "closed",
+			db:            &mockDB{err: cql.ErrClosed},
+			wantReadiness: http.StatusServiceUnavailable,
+			wantLiveness:  http.StatusServiceUnavailable,
+			wantStatus:    Status{Error: cql.ErrClosed.Error()},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []struct {
+				h    http.Handler
+				code int
+			}{
+				{h: ReadinessHandler(tt.db), code: tt.wantReadiness},
+				{h: LivenessHandler(tt.db), code: tt.wantLiveness},
+			} {
+				var (
+					s   Status
+					rec = httptest.NewRecorder()
+				)
+
+				c.h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+				assert.Equal(t, c.code, rec.Code)
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&s))
+
+				want := tt.wantStatus
+				want.Healthy = c.code == http.StatusOK
+
+				assert.Equal(t, want, s)
+			}
+		})
+	}
+}
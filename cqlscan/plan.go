@@ -0,0 +1,108 @@
+package cqlscan
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var plans sync.Map
+
+type plan struct {
+	fields map[string][]int
+}
+
+func planFor(t reflect.Type) *plan {
+	if p, ok := plans.Load(t); ok {
+		return p.(*plan)
+	}
+
+	p := plan{fields: make(map[string][]int)}
+	p.walk(t, nil)
+
+	pp, _ := plans.LoadOrStore(t, &p)
+
+	return pp.(*plan)
+}
+
+func (p *plan) walk(t reflect.Type, index []int) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("cql")
+
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && tag == "" {
+			// As with encoding/json, a pointer to an unexported struct can
+			// not be allocated through reflection so its fields are skipped.
+			if !f.IsExported() && f.Type.Kind() == reflect.Ptr {
+				continue
+			}
+
+			if ft := indirectType(f.Type); ft.Kind() == reflect.Struct {
+				embedded = append(embedded, f)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name := tag
+
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		if _, ok := p.fields[name]; !ok {
+			p.fields[name] = appendIndex(index, f.Index...)
+		}
+	}
+
+	// Embedded structs are walked once every field of the current level is
+	// registered so the shallowest field wins, as with Go field promotion.
+	for _, f := range embedded {
+		p.walk(indirectType(f.Type), appendIndex(index, f.Index...))
+	}
+}
+
+func (p *plan) field(v reflect.Value, name string) (reflect.Value, bool) {
+	index, ok := p.fields[name]
+
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+
+	return t
+}
+
+func appendIndex(index []int, xs ...int) []int {
+	res := make([]int, 0, len(index)+len(xs))
+
+	return append(append(res, index...), xs...)
+}
@@ -0,0 +1,146 @@
+package cqlscan
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+var (
+	ErrNoColumns   = errors.New("The scanner does not describe its columns")
+	ErrInvalidType = errors.New("The destination must be a pointer to a struct")
+)
+
+type ErrMissingField struct{ Column string }
+
+func (emf ErrMissingField) Error() string {
+	return fmt.Sprintf("no field matching the %q column", emf.Column)
+}
+
+func destinations(v reflect.Value, cs []cql.ColumnInfo) ([]interface{}, error) {
+	var (
+		p  = planFor(v.Type())
+		vs = make([]interface{}, len(cs))
+	)
+
+	for i, c := range cs {
+		f, ok := p.field(v, c.Name)
+
+		if !ok {
+			return nil, ErrMissingField{Column: c.Name}
+		}
+
+		vs[i] = f.Addr().Interface()
+	}
+
+	return vs, nil
+}
+
+func structValue(dest interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(dest)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, ErrInvalidType
+	}
+
+	return v.Elem(), nil
+}
+
+// Scan scans the row returned by the scanner into the struct pointed by dest.
+// The scanner has to implement cql.ColumnDescriber.
+func Scan(sc cql.Scanner, dest interface{}) error {
+	v, err := structValue(dest)
+
+	if err != nil {
+		return err
+	}
+
+	cd, ok := sc.(cql.ColumnDescriber)
+
+	if !ok {
+		return ErrNoColumns
+	}
+
+	cs := cd.Columns()
+
+	if len(cs) == 0 {
+		// The query most likely failed, the scanner reports why.
+		return noColumnsError(sc.Scan())
+	}
+
+	vs, err := destinations(v, cs)
+
+	if err != nil {
+		return err
+	}
+
+	return sc.Scan(vs...)
+}
+
+func Get[T any](sc cql.Scanner) (T, error) {
+	var t T
+
+	err := Scan(sc, &t)
+
+	return t, err
+}
+
+// Select scans every row returned by the cursor and closes it. The cursor has
+// to implement cql.ColumnDescriber.
+func Select[T any](cur cql.Cursor) ([]T, error) {
+	var (
+		res []T
+		t   T
+	)
+
+	if _, err := structValue(&t); err != nil {
+		return nil, errors.Combine(err, cur.Close())
+	}
+
+	cd, ok := cur.(cql.ColumnDescriber)
+
+	if !ok {
+		return nil, errors.Combine(ErrNoColumns, cur.Close())
+	}
+
+	cs := cd.Columns()
+
+	if len(cs) == 0 {
+		return nil, noColumnsError(cur.Close())
+	}
+
+	for {
+		var (
+			t T
+
+			vs, err = destinations(reflect.ValueOf(&t).Elem(), cs)
+		)
+
+		if err != nil {
+			return nil, errors.Combine(err, cur.Close())
+		}
+
+		if !cur.Scan(vs...) {
+			break
+		}
+
+		res = append(res, t)
+	}
+
+	if err := cur.Close(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func noColumnsError(err error) error {
+	if err != nil {
+		return err
+	}
+
+	return ErrNoColumns
+}
@@ -0,0 +1,181 @@
+package cqlscan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/cql"
+)
+
+type staticRows struct {
+	columns []string
+	rows    [][]interface{}
+
+	err    error
+	closed bool
+}
+
+func (sr *staticRows) Columns() []cql.ColumnInfo {
+	cs := make([]cql.ColumnInfo, len(sr.columns))
+
+	for i, c := range sr.columns {
+		cs[i] = cql.ColumnInfo{Name: c}
+	}
+
+	return cs
+}
+
+func (sr *staticRows) next(vs []interface{}) bool {
+	if len(sr.rows) == 0 {
+		return false
+	}
+
+	row := sr.rows[0]
+	sr.rows = sr.rows[1:]
+
+	for i, v := range vs {
+		dv := reflect.ValueOf(v).Elem()
+
+		if row[i] == nil {
+			dv.Set(reflect.Zero(dv.Type()))
+			continue
+		}
+
+		rv := reflect.ValueOf(row[i])
+
+		if dv.Kind() == reflect.Ptr {
+			pv := reflect.New(dv.Type().Elem())
+			pv.Elem().Set(rv)
+			rv = pv
+		}
+
+		dv.Set(rv)
+	}
+
+	return true
+}
+
+func (sr *staticRows) Scan(vs ...interface{}) error {
+	if !sr.next(vs) {
+		return cql.ErrNoRows
+	}
+
+	return nil
+}
+
+type staticCursor struct{ *staticRows }
+
+func (sc staticCursor) Scan(vs ...interface{}) bool { return sc.next(vs) }
+
+func (sc staticCursor) Close() error {
+	sc.closed = true
+	return sc.err
+}
+
+type Base struct {
+	ID int
+}
+
+type Named struct {
+	Base
+
+	Name     string  `cql:"full_name"`
+	Nickname *string `cql:"nick_name"`
+	Ignored  string  `cql:"-"`
+}
+
+func TestGet(t *testing.T) {
+	res, err := Get[Named](
+		&staticRows{
+			columns: []string{"id", "full_name", "nick_name"},
+			rows:    [][]interface{}{{1, "foo", "bar"}},
+		},
+	)
+
+	nick := "bar"
+
+	assert.NoError(t, err)
+	assert.Equal(t, Named{Base: Base{ID: 1}, Name: "foo", Nickname: &nick}, res)
+
+	_, err = Get[Named](&staticRows{columns: []string{"id", "ignored"}})
+	assert.Equal(t, ErrMissingField{Column: "ignored"}, err)
+
+	_, err = Get[Named](&staticRows{columns: []string{"id"}})
+	assert.Equal(t, cql.ErrNoRows, err)
+
+	_, err = Get[int](&staticRows{columns: []string{"id"}})
+	assert.Equal(t, ErrInvalidType, err)
+}
+
+func TestSelect(t *testing.T) {
+	type embedded struct {
+		*Base
+
+		Name string
+	}
+
+	sr := &staticRows{
+		columns: []string{"id", "name"},
+		rows:    [][]interface{}{{1, "foo"}, {2, "bar"}},
+	}
+
+	res, err := Select[embedded](staticCursor{sr})
+
+	assert.NoError(t, err)
+	assert.True(t, sr.closed)
+	assert.Equal(
+		t,
+		[]embedded{
+			{Base: &Base{ID: 1}, Name: "foo"},
+			{Base: &Base{ID: 2}, Name: "bar"},
+		},
+		res,
+	)
+}
+
+type base struct {
+	ID int
+}
+
+func TestUnexportedEmbedded(t *testing.T) {
+	type (
+		withPtr struct {
+			*base
+
+			Name string
+		}
+
+		withValue struct {
+			base
+
+			Name string
+		}
+	)
+
+	rows := func() *staticRows {
+		return &staticRows{
+			columns: []string{"id", "name"},
+			rows:    [][]interface{}{{1, "foo"}},
+		}
+	}
+
+	_, err := Get[withPtr](rows())
+	assert.Equal(t, ErrMissingField{Column: "id"}, err)
+
+	res, err := Get[withValue](rows())
+	assert.NoError(t, err)
+	assert.Equal(t, withValue{base: base{ID: 1}, Name: "foo"}, res)
+}
+
+func TestPlanCache(t *testing.T) {
+	typ := reflect.TypeOf(Named{})
+
+	assert.Same(t, planFor(typ), planFor(typ))
+	assert.Equal(
+		t,
+		map[string][]int{"id": {0, 0}, "full_name": {1}, "nick_name": {2}},
+		planFor(typ).fields,
+	)
+}
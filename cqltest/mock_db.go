@@ -0,0 +1,159 @@
+package cqltest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/upfluence/cql"
+)
+
+// MockDB is an in memory cql.DB for the middleware tests, every operation
+// succeeds unless it is given an error through Errs or Err.
+type MockDB struct {
+	// Errs are returned one per operation, in order, before falling back on
+	// Err.
+	Errs []error
+	Err  error
+
+	// Cursors are returned one per Query, in order, before falling back on a
+	// cursor yielding Rows rows.
+	Cursors []*MockCursor
+	Rows    int
+
+	// Applied is the outcome of the lightweight transactions.
+	Applied bool
+
+	// ColumnInfos describes the columns of the rows returned by QueryRow.
+	ColumnInfos []cql.ColumnInfo
+
+	// ExecFunc, when set, runs the Exec operations instead of the mock.
+	ExecFunc func(context.Context, string, ...interface{}) error
+
+	mu    sync.Mutex
+	calls int
+}
+
+// Calls returns the number of operations run so far.
+func (db *MockDB) Calls() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.calls
+}
+
+// ResetCalls sets the number of operations run back to zero.
+func (db *MockDB) ResetCalls() {
+	db.mu.Lock()
+	db.calls = 0
+	db.mu.Unlock()
+}
+
+func (db *MockDB) next() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.calls++
+
+	if len(db.Errs) == 0 {
+		return db.Err
+	}
+
+	err := db.Errs[0]
+	db.Errs = db.Errs[1:]
+
+	return err
+}
+
+func (db *MockDB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	if db.ExecFunc != nil {
+		db.mu.Lock()
+		db.calls++
+		db.mu.Unlock()
+
+		return db.ExecFunc(ctx, stmt, vs...)
+	}
+
+	return db.next()
+}
+
+func (db *MockDB) ExecCAS(context.Context, string, ...interface{}) cql.CASScanner {
+	return MockCASScanner{Applied: db.Applied, Err: db.next()}
+}
+
+func (db *MockDB) QueryRow(context.Context, string, ...interface{}) cql.Scanner {
+	return MockScanner{ColumnInfos: db.ColumnInfos, Err: db.next()}
+}
+
+func (db *MockDB) Query(context.Context, string, ...interface{}) cql.Cursor {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.calls++
+
+	if len(db.Cursors) == 0 {
+		return &MockCursor{Rows: db.Rows}
+	}
+
+	c := db.Cursors[0]
+	db.Cursors = db.Cursors[1:]
+
+	return c
+}
+
+func (db *MockDB) Batch(context.Context, cql.BatchType, ...cql.Option) cql.Batch {
+	return &MockBatch{db: db}
+}
+
+type MockScanner struct {
+	ColumnInfos []cql.ColumnInfo
+	Err         error
+}
+
+func (sc MockScanner) Columns() []cql.ColumnInfo { return sc.ColumnInfos }
+func (sc MockScanner) Scan(...interface{}) error { return sc.Err }
+
+type MockCASScanner struct {
+	Applied bool
+	Err     error
+}
+
+func (sc MockCASScanner) ScanCAS(...interface{}) (bool, error) {
+	return sc.Applied && sc.Err == nil, sc.Err
+}
+
+// MockCursor yields Rows rows then returns Err on Close.
+type MockCursor struct {
+	Rows int
+	Err  error
+}
+
+func (c *MockCursor) Scan(...interface{}) bool {
+	if c.Rows == 0 {
+		return false
+	}
+
+	c.Rows--
+
+	return true
+}
+
+func (c *MockCursor) Close() error { return c.Err }
+
+// MockBatch records its statements, it runs as a single operation of its DB.
+type MockBatch struct {
+	Statements []string
+
+	db *MockDB
+}
+
+func (b *MockBatch) Query(stmt string, _ ...interface{}) {
+	b.Statements = append(b.Statements, stmt)
+}
+
+func (b *MockBatch) Exec() error { return b.db.next() }
+
+func (b *MockBatch) ExecCAS() (bool, cql.Cursor, error) {
+	err := b.db.next()
+
+	return b.db.Applied && err == nil, nil, err
+}
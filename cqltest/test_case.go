@@ -113,8 +113,12 @@ func (tc *TestCase) Run(t *testing.T, fn func(t *testing.T, db cql.DB)) {
 		t.Fatalf("Cannot create testing keyspace: %+v", err)
 	}
 
+	cql.Close(context.Background(), db)
+
 	db = tc.buildDB(t, keyspace)
 
+	defer cql.Close(context.Background(), db)
+
 	for _, mfn := range tc.mfns {
 		if err := mfn(db).Up(context.Background()); err != nil {
 			t.Fatalf("can not proceed the migration up: %v", err.Error())
@@ -0,0 +1,66 @@
+package cqlutil
+
+import (
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/upfluence/errors"
+)
+
+var ErrMissingUsername = errors.New("A username is required along the password")
+
+// Credentials authenticates the connections with the given username and
+// password.
+func Credentials(username, password string) Option {
+	return func(b *builder) {
+		b.username = username
+		b.password = password
+		b.passwordFile = ""
+	}
+}
+
+// PasswordFile authenticates the connections with the given username and the
+// password stored in the file, the file is read again when it changes so a
+// rotated password is used by the connections opened afterwards.
+func PasswordFile(username, path string) Option {
+	return func(b *builder) {
+		b.username = username
+		b.password = ""
+		b.passwordFile = path
+	}
+}
+
+type fileAuthenticator struct {
+	username string
+	password *reloader[string]
+}
+
+func (fa *fileAuthenticator) Challenge(req []byte) ([]byte, gocql.Authenticator, error) {
+	password, err := fa.password.get()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gocql.PasswordAuthenticator{
+		Username: fa.username,
+		Password: password,
+	}.Challenge(req)
+}
+
+func (*fileAuthenticator) Success([]byte) error { return nil }
+
+func parsePassword(bufs [][]byte) (string, error) {
+	return strings.TrimRight(string(bufs[0]), "\r\n"), nil
+}
+
+func (b *builder) authenticator() gocql.Authenticator {
+	if b.passwordFile != "" {
+		return &fileAuthenticator{
+			username: b.username,
+			password: newReloader(parsePassword, b.passwordFile),
+		}
+	}
+
+	return gocql.PasswordAuthenticator{Username: b.username, Password: b.password}
+}
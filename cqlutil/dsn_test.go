@@ -0,0 +1,147 @@
+package cqlutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+func TestParseDSN(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		dsn  string
+
+		wantURL string
+		wantCC  func(*testing.T, *gocql.ClusterConfig)
+		wantErr error
+	}{
+		{
+			name:    "full",
+			dsn:     "cassandra://user:pass@h1,h2:9043/ks?consistency=local_quorum&timeout=5s&dc=eu1&compression=snappy",
+			wantURL: "h1,h2",
+			wantCC: func(t *testing.T, cc *gocql.ClusterConfig) {
+				assert.Equal(t, []string{"h1", "h2"}, cc.Hosts)
+				assert.Equal(t, 9043, cc.Port)
+				assert.Equal(t, "ks", cc.Keyspace)
+				assert.Equal(t, gocql.LocalQuorum, cc.Consistency)
+				assert.Equal(t, 5*time.Second, cc.Timeout)
+				assert.Equal(t, &gocql.SnappyCompressor{}, cc.Compressor)
+				assert.NotNil(t, cc.PoolConfig.HostSelectionPolicy)
+				assert.Equal(
+					t,
+					gocql.PasswordAuthenticator{Username: "user", Password: "pass"},
+					cc.Authenticator,
+				)
+			},
+		},
+		{
+			name:    "host ports",
+			dsn:     "cassandra://h1:9043,[::1]:9044,h3",
+			wantURL: "h1:9043,[::1]:9044,h3",
+			wantCC: func(t *testing.T, cc *gocql.ClusterConfig) {
+				assert.Equal(t, []string{"h1:9043", "[::1]:9044", "h3"}, cc.Hosts)
+				assert.Equal(t, 9042, cc.Port)
+			},
+		},
+		{
+			name:    "ipv6 host",
+			dsn:     "cassandra://[::1]:9043",
+			wantURL: "::1",
+			wantCC: func(t *testing.T, cc *gocql.ClusterConfig) {
+				assert.Equal(t, []string{"::1"}, cc.Hosts)
+				assert.Equal(t, 9043, cc.Port)
+			},
+		},
+		{
+			name:    "invalid host port",
+			dsn:     "cassandra://h1:foo,h2:9043",
+			wantErr: ErrInvalidDSN,
+		},
+		{
+			name:    "hosts only",
+			dsn:     "cassandra://127.0.0.1",
+			wantURL: "127.0.0.1",
+			wantCC: func(t *testing.T, cc *gocql.ClusterConfig) {
+				assert.Equal(t, 9042, cc.Port)
+				assert.Nil(t, cc.Authenticator)
+			},
+		},
+		{
+			name:    "tls",
+			dsn:     "cassandra://127.0.0.1?tls_ca_file=/ca.pem&tls_server_name=cassandra&password_file=/pwd&username=foo",
+			wantURL: "127.0.0.1",
+			wantCC: func(t *testing.T, cc *gocql.ClusterConfig) {
+				assert.Equal(t, "cassandra", cc.SslOpts.ServerName)
+				assert.NotNil(t, cc.SslOpts.VerifyConnection)
+				assert.Nil(t, cc.SslOpts.GetClientCertificate)
+				assert.IsType(t, &fileAuthenticator{}, cc.Authenticator)
+			},
+		},
+		{
+			name:    "tls insecure skip verify",
+			dsn:     "cassandra://127.0.0.1?tls_insecure_skip_verify=true",
+			wantURL: "127.0.0.1",
+			wantCC: func(t *testing.T, cc *gocql.ClusterConfig) {
+				assert.True(t, cc.SslOpts.InsecureSkipVerify)
+				assert.Nil(t, cc.SslOpts.VerifyConnection)
+			},
+		},
+		{
+			name:    "tls without server name",
+			dsn:     "cassandra://127.0.0.1?tls_ca_file=/ca.pem",
+			wantURL: "127.0.0.1",
+			wantCC: func(t *testing.T, cc *gocql.ClusterConfig) {
+				assert.Nil(t, cc.SslOpts)
+				assert.IsType(t, hostDialer{}, cc.Dialer)
+				assert.Zero(t, cc.WriteCoalesceWaitTime)
+			},
+		},
+		{
+			name:    "password without username",
+			dsn:     "cassandra://127.0.0.1?password_file=/pwd",
+			wantErr: ErrMissingUsername,
+		},
+		{name: "bad scheme", dsn: "mysql://127.0.0.1", wantErr: ErrInvalidDSN},
+		{
+			name:    "unknown parameter",
+			dsn:     "cassandra://127.0.0.1?foo=bar",
+			wantErr: ErrInvalidDSN,
+		},
+		{
+			name:    "invalid consistency",
+			dsn:     "cassandra://127.0.0.1?consistency=most",
+			wantErr: cql.ErrInvalidConsistency,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				b  builder
+				cc *gocql.ClusterConfig
+			)
+
+			opts, err := ParseDSN(tt.dsn)
+
+			if err == nil {
+				for _, opt := range opts {
+					opt(&b)
+				}
+
+				cc, err = b.clusterConfig()
+			}
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantURL, b.cassandraURL)
+			tt.wantCC(t, cc)
+		})
+	}
+}
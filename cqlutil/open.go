@@ -18,6 +18,24 @@ var NoGossip = WithCQLOption(
 	},
 )
 
+// LocalDC routes the queries to the hosts of the given datacenter first, the
+// hosts of the other datacenters are only used as a fallback. When gossip is
+// disabled the datacenter of the hosts can not be discovered, they are then
+// all considered as remote ones and used in a round robin fashion.
+func LocalDC(dc string) Option {
+	return func(b *builder) { b.localDC = dc }
+}
+
+// TokenAware routes the queries to a replica owning the partition key.
+var TokenAware Option = func(b *builder) { b.tokenAware = true }
+
+// ShuffleReplicas routes the queries to a replica owning the partition key,
+// picked randomly among them.
+var ShuffleReplicas Option = func(b *builder) {
+	b.tokenAware = true
+	b.shuffleReplicas = true
+}
+
 func WithCQLOption(fn func(*gocql.ClusterConfig)) Option {
 	return func(o *builder) { o.cqlOptions = append(o.cqlOptions, fn) }
 }
@@ -38,10 +56,34 @@ func Consistency(c gocql.Consistency) Option {
 	return WithCQLOption(func(cc *gocql.ClusterConfig) { cc.Consistency = c })
 }
 
+func SerialConsistency(c gocql.SerialConsistency) Option {
+	return WithCQLOption(
+		func(cc *gocql.ClusterConfig) { cc.SerialConsistency = c },
+	)
+}
+
 func Timeout(t time.Duration) Option {
 	return WithCQLOption(func(cc *gocql.ClusterConfig) { cc.Timeout = t })
 }
 
+func ConnectTimeout(t time.Duration) Option {
+	return WithCQLOption(func(cc *gocql.ClusterConfig) { cc.ConnectTimeout = t })
+}
+
+func NumConns(n int) Option {
+	return WithCQLOption(func(cc *gocql.ClusterConfig) { cc.NumConns = n })
+}
+
+func Compression(c gocql.Compressor) Option {
+	return WithCQLOption(func(cc *gocql.ClusterConfig) { cc.Compressor = c })
+}
+
+// ProtoVersion pins the native protocol version, it is negotiated with the
+// cluster when not set or set to 0.
+func ProtoVersion(v int) Option {
+	return WithCQLOption(func(cc *gocql.ClusterConfig) { cc.ProtoVersion = v })
+}
+
 func Port(p int) Option {
 	return WithCQLOption(func(cc *gocql.ClusterConfig) { cc.Port = p })
 }
@@ -53,18 +95,66 @@ func RetryPolicy(p gocql.RetryPolicy) Option {
 type builder struct {
 	cassandraURL string
 
+	username     string
+	password     string
+	passwordFile string
+
+	tls        TLSConfig
+	tlsEnabled bool
+
+	localDC         string
+	tokenAware      bool
+	shuffleReplicas bool
+
 	cqlOptions  []func(*gocql.ClusterConfig)
 	middlewares []cql.MiddlewareFactory
 }
 
-func (b *builder) clusterConfig() *gocql.ClusterConfig {
+func (b *builder) hostSelectionPolicy() gocql.HostSelectionPolicy {
+	var p gocql.HostSelectionPolicy
+
+	switch {
+	case b.localDC != "":
+		p = gocql.DCAwareRoundRobinPolicy(b.localDC)
+	case b.tokenAware:
+		p = gocql.RoundRobinHostPolicy()
+	default:
+		return nil
+	}
+
+	switch {
+	case !b.tokenAware:
+		return p
+	case b.shuffleReplicas:
+		return gocql.TokenAwareHostPolicy(p, gocql.ShuffleReplicas())
+	}
+
+	return gocql.TokenAwareHostPolicy(p)
+}
+
+func (b *builder) clusterConfig() (*gocql.ClusterConfig, error) {
 	cc := gocql.NewCluster(strings.Split(b.cassandraURL, ",")...)
 
+	switch {
+	case b.username != "":
+		cc.Authenticator = b.authenticator()
+	case b.password != "" || b.passwordFile != "":
+		return nil, ErrMissingUsername
+	}
+
+	if p := b.hostSelectionPolicy(); p != nil {
+		cc.PoolConfig.HostSelectionPolicy = p
+	}
+
 	for _, opt := range b.cqlOptions {
 		opt(cc)
 	}
 
-	return cc
+	if b.tlsEnabled {
+		b.tls.apply(cc)
+	}
+
+	return cc, nil
 }
 
 func fetchString(env, fallback string) string {
@@ -83,7 +173,6 @@ func Open(opts ...Option) (cql.DB, error) {
 		cqlOptions: []func(*gocql.ClusterConfig){
 			func(cc *gocql.ClusterConfig) {
 				cc.Keyspace = fetchString("CASSANDRA_KEYSPACE", "test")
-				cc.ProtoVersion = 3
 				cc.Consistency = gocql.Quorum
 				cc.Timeout = 15 * time.Second
 				cc.RetryPolicy = &gocql.SimpleRetryPolicy{NumRetries: 3}
@@ -91,21 +180,41 @@ func Open(opts ...Option) (cql.DB, error) {
 		},
 	}
 
-	for _, opt := range opts {
+	envOpts, err := envOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range append(envOpts, opts...) {
 		opt(&b)
 	}
 
-	sess, err := b.clusterConfig().CreateSession()
+	cc, err := b.clusterConfig()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var db cql.DB = backend.NewDB(sess)
+	var (
+		pvo = backend.NewProtoVersionObserver(cc.FrameHeaderObserver)
+		ht  = backend.NewHostTracker(cc.PoolConfig.HostSelectionPolicy)
+	)
+
+	cc.FrameHeaderObserver = pvo
+	cc.PoolConfig.HostSelectionPolicy = ht
+
+	sess, err := cc.CreateSession()
 
-	for _, m := range b.middlewares {
-		db = m.Wrap(db)
+	if err != nil {
+		return nil, err
 	}
 
-	return db, nil
+	return cql.Chain(b.middlewares...).Wrap(
+		backend.NewDB(
+			sess,
+			backend.WithProtoVersionObserver(pvo),
+			backend.WithHostTracker(ht),
+		),
+	), nil
 }
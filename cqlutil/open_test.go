@@ -0,0 +1,48 @@
+package cqlutil
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostSelectionPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+
+		want string
+	}{
+		{name: "default", want: "<nil>"},
+		{name: "local dc", opts: []Option{LocalDC("eu1")}, want: "*gocql.dcAwareRR"},
+		{
+			name: "token aware",
+			opts: []Option{TokenAware},
+			want: "*gocql.tokenAwareHostPolicy",
+		},
+		{
+			name: "local dc with gossip disabled",
+			opts: []Option{NoGossip, LocalDC("eu1"), ShuffleReplicas},
+			want: "*gocql.tokenAwareHostPolicy",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var b builder
+
+			for _, opt := range tt.opts {
+				opt(&b)
+			}
+
+			cc, err := b.clusterConfig()
+			require.NoError(t, err)
+
+			assert.Equal(
+				t,
+				tt.want,
+				fmt.Sprintf("%T", cc.PoolConfig.HostSelectionPolicy),
+			)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package cqlutil
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// reloader parses a set of files and parses them again as soon as one of
+// them is modified, which lets rotated secrets be picked up without
+// restarting the process.
+type reloader[T any] struct {
+	paths []string
+	parse func([][]byte) (T, error)
+
+	mu     sync.Mutex
+	stamps []fileStamp
+	value  T
+}
+
+func newReloader[T any](parse func([][]byte) (T, error), paths ...string) *reloader[T] {
+	return &reloader[T]{paths: paths, parse: parse}
+}
+
+func (r *reloader[T]) get() (T, error) {
+	var (
+		zero T
+
+		stamps = make([]fileStamp, len(r.paths))
+	)
+
+	for i, p := range r.paths {
+		fi, err := os.Stat(p)
+
+		if err != nil {
+			return zero, err
+		}
+
+		stamps[i] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stamps != nil && equalStamps(r.stamps, stamps) {
+		return r.value, nil
+	}
+
+	bufs := make([][]byte, len(r.paths))
+
+	for i, p := range r.paths {
+		buf, err := os.ReadFile(p)
+
+		if err != nil {
+			return zero, err
+		}
+
+		bufs[i] = buf
+	}
+
+	v, err := r.parse(bufs)
+
+	if err != nil {
+		return zero, err
+	}
+
+	r.value = v
+	r.stamps = stamps
+
+	return v, nil
+}
+
+func equalStamps(l, r []fileStamp) bool {
+	for i := range l {
+		if !l[i].modTime.Equal(r[i].modTime) || l[i].size != r[i].size {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,50 @@
+package cqlutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloader(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "password")
+		calls int
+
+		r = newReloader(
+			func(bufs [][]byte) (string, error) {
+				calls++
+				return parsePassword(bufs)
+			},
+			path,
+		)
+	)
+
+	_, err := r.get()
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(path, []byte("foo\n"), 0600))
+
+	v, err := r.get()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", v)
+
+	v, err = r.get()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", v)
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, os.WriteFile(path, []byte("barbaz"), 0600))
+	assert.NoError(
+		t,
+		os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)),
+	)
+
+	v, err = r.get()
+	assert.NoError(t, err)
+	assert.Equal(t, "barbaz", v)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,221 @@
+package cqlutil
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+var ErrInvalidCompression = errors.New("Invalid compression")
+
+// setting is a configuration knob settable through a DSN query parameter and,
+// when env is not empty, through an environment variable.
+type setting struct {
+	env   string
+	parse func(string) (Option, error)
+}
+
+var settings = map[string]setting{
+	"consistency": {
+		env: "CASSANDRA_CONSISTENCY",
+		parse: func(v string) (Option, error) {
+			c, err := cql.ParseConsistency(v)
+
+			return Consistency(gocql.Consistency(c)), err
+		},
+	},
+	"serial_consistency": {
+		env: "CASSANDRA_SERIAL_CONSISTENCY",
+		parse: func(v string) (Option, error) {
+			c, err := cql.ParseSerialConsistency(v)
+
+			return SerialConsistency(gocql.SerialConsistency(c)), err
+		},
+	},
+	"timeout":         {env: "CASSANDRA_TIMEOUT", parse: durationSetting(Timeout)},
+	"connect_timeout": {env: "CASSANDRA_CONNECT_TIMEOUT", parse: durationSetting(ConnectTimeout)},
+	"port":            {env: "CASSANDRA_PORT", parse: intSetting(Port)},
+	"proto_version":   {env: "CASSANDRA_PROTO_VERSION", parse: intSetting(ProtoVersion)},
+	"num_conns":       {env: "CASSANDRA_NUM_CONNS", parse: intSetting(NumConns)},
+	"compression":     {env: "CASSANDRA_COMPRESSION", parse: parseCompression},
+	"username": {
+		env:   "CASSANDRA_USERNAME",
+		parse: stringSetting(func(b *builder, v string) { b.username = v }),
+	},
+	"password": {
+		env:   "CASSANDRA_PASSWORD",
+		parse: stringSetting(func(b *builder, v string) { b.password = v }),
+	},
+	"password_file": {
+		env:   "CASSANDRA_PASSWORD_FILE",
+		parse: stringSetting(func(b *builder, v string) { b.passwordFile = v }),
+	},
+	"tls": {
+		env:   "CASSANDRA_TLS",
+		parse: boolSetting(func(b *builder, v bool) { b.tlsEnabled = v }),
+	},
+	"tls_ca_file": {
+		env:   "CASSANDRA_TLS_CA_FILE",
+		parse: tlsSetting(func(tc *TLSConfig, v string) { tc.CAFile = v }),
+	},
+	"tls_cert_file": {
+		env:   "CASSANDRA_TLS_CERT_FILE",
+		parse: tlsSetting(func(tc *TLSConfig, v string) { tc.CertFile = v }),
+	},
+	"tls_key_file": {
+		env:   "CASSANDRA_TLS_KEY_FILE",
+		parse: tlsSetting(func(tc *TLSConfig, v string) { tc.KeyFile = v }),
+	},
+	"tls_server_name": {
+		env:   "CASSANDRA_TLS_SERVER_NAME",
+		parse: tlsSetting(func(tc *TLSConfig, v string) { tc.ServerName = v }),
+	},
+	"tls_insecure_skip_verify": {
+		env: "CASSANDRA_TLS_INSECURE_SKIP_VERIFY",
+		parse: boolSetting(
+			func(b *builder, v bool) {
+				b.tls.InsecureSkipVerify = v
+				b.tlsEnabled = true
+			},
+		),
+	},
+	"dc": {
+		env:   "CASSANDRA_LOCAL_DC",
+		parse: stringSetting(func(b *builder, v string) { b.localDC = v }),
+	},
+	"token_aware": {
+		env:   "CASSANDRA_TOKEN_AWARE",
+		parse: boolSetting(func(b *builder, v bool) { b.tokenAware = v }),
+	},
+	"shuffle_replicas": {
+		env: "CASSANDRA_SHUFFLE_REPLICAS",
+		parse: boolSetting(
+			func(b *builder, v bool) {
+				b.shuffleReplicas = v
+				b.tokenAware = b.tokenAware || v
+			},
+		),
+	},
+}
+
+func durationSetting(fn func(time.Duration) Option) func(string) (Option, error) {
+	return func(v string) (Option, error) {
+		d, err := time.ParseDuration(v)
+
+		return fn(d), err
+	}
+}
+
+func intSetting(fn func(int) Option) func(string) (Option, error) {
+	return func(v string) (Option, error) {
+		i, err := strconv.Atoi(v)
+
+		return fn(i), err
+	}
+}
+
+func stringSetting(fn func(*builder, string)) func(string) (Option, error) {
+	return func(v string) (Option, error) {
+		return func(b *builder) { fn(b, v) }, nil
+	}
+}
+
+func tlsSetting(fn func(*TLSConfig, string)) func(string) (Option, error) {
+	return stringSetting(
+		func(b *builder, v string) {
+			fn(&b.tls, v)
+			b.tlsEnabled = true
+		},
+	)
+}
+
+func boolSetting(fn func(*builder, bool)) func(string) (Option, error) {
+	return func(v string) (Option, error) {
+		ok, err := strconv.ParseBool(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return func(b *builder) { fn(b, ok) }, nil
+	}
+}
+
+func parseCompression(v string) (Option, error) {
+	switch v {
+	case "snappy":
+		return Compression(&gocql.SnappyCompressor{}), nil
+	case "", "none":
+		return Compression(nil), nil
+	}
+
+	return nil, errors.Wrapf(ErrInvalidCompression, "%q", v)
+}
+
+func parseSetting(k, v string) (Option, error) {
+	s, ok := settings[k]
+
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidDSN, "unknown %q parameter", k)
+	}
+
+	opt, err := s.parse(v)
+
+	return opt, errors.Wrapf(err, "invalid %q value", k)
+}
+
+func settingKeys() []string {
+	ks := make([]string, 0, len(settings))
+
+	for k := range settings {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	return ks
+}
+
+func envOptions() ([]Option, error) {
+	var opts []Option
+
+	if dsn := os.Getenv("CASSANDRA_DSN"); dsn != "" {
+		dsnOpts, err := ParseDSN(dsn)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "CASSANDRA_DSN")
+		}
+
+		opts = append(opts, dsnOpts...)
+	}
+
+	for _, k := range settingKeys() {
+		s := settings[k]
+
+		if s.env == "" {
+			continue
+		}
+
+		v := os.Getenv(s.env)
+
+		if v == "" {
+			continue
+		}
+
+		opt, err := s.parse(v)
+
+		if err != nil {
+			return nil, errors.Wrap(err, s.env)
+		}
+
+		opts = append(opts, opt)
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,177 @@
+package cqlutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+
+	"github.com/gocql/gocql"
+	"github.com/upfluence/errors"
+)
+
+var errInvalidCA = errors.New("No certificate found in the CA file")
+
+type TLSConfig struct {
+	// CAFile holds the PEM encoded certificates used to verify the server,
+	// the system pool is used when empty.
+	CAFile string
+
+	CertFile string
+	KeyFile  string
+
+	// ServerName is the name the server certificate is verified against,
+	// the host each node is dialed with is used when empty.
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// TLS encrypts the connections, the CA and the client certificate files are
+// read again when they change so rotated certificates are used by the
+// connections opened afterwards.
+func TLS(c TLSConfig) Option {
+	return func(b *builder) {
+		b.tls = c
+		b.tlsEnabled = true
+	}
+}
+
+func parseCertPool(bufs [][]byte) (*x509.CertPool, error) {
+	p := x509.NewCertPool()
+
+	if !p.AppendCertsFromPEM(bufs[0]) {
+		return nil, errInvalidCA
+	}
+
+	return p, nil
+}
+
+func parseKeyPair(bufs [][]byte) (*tls.Certificate, error) {
+	c, err := tls.X509KeyPair(bufs[0], bufs[1])
+
+	return &c, err
+}
+
+type verifier struct {
+	roots *reloader[*x509.CertPool]
+}
+
+func (v *verifier) verifyConnection(name string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error { return v.verify(cs, name) }
+}
+
+func (v *verifier) verify(cs tls.ConnectionState, name string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("No certificate presented by the server")
+	}
+
+	opts := x509.VerifyOptions{
+		DNSName:       name,
+		Intermediates: x509.NewCertPool(),
+	}
+
+	if v.roots != nil {
+		roots, err := v.roots.get()
+
+		if err != nil {
+			return errors.Wrap(err, "cant load the CA file")
+		}
+
+		opts.Roots = roots
+	}
+
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+
+	return err
+}
+
+// hostDialer runs the TLS handshake of the connections itself, so the server
+// certificate is verified against the host the node is dialed with.
+type hostDialer struct {
+	dialer gocql.Dialer
+	cfg    *tls.Config
+	v      *verifier
+}
+
+func (d hostDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := d.dialer.DialContext(ctx, network, addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := d.cfg.Clone()
+	cfg.ServerName = host
+	cfg.VerifyConnection = d.v.verifyConnection(host)
+
+	tconn := tls.Client(conn, cfg)
+
+	if err := tconn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return tconn, nil
+}
+
+// apply encrypts the connections of the cluster, it is applied once the
+// other options are so the dialer honors their timeouts.
+func (tc TLSConfig) apply(cc *gocql.ClusterConfig) {
+	cfg, v := tc.config()
+
+	if v == nil || tc.ServerName != "" {
+		cc.SslOpts = &gocql.SslOptions{Config: cfg}
+		return
+	}
+
+	d := cc.Dialer
+
+	if d == nil {
+		d = &net.Dialer{Timeout: cc.ConnectTimeout, KeepAlive: cc.SocketKeepalive}
+	}
+
+	cc.Dialer = hostDialer{dialer: d, cfg: cfg, v: v}
+
+	// gocql does not coalesce the writes of the connections it encrypts.
+	cc.WriteCoalesceWaitTime = 0
+}
+
+// config returns the TLS config along its verifier, which is nil when the
+// server certificate is not verified.
+func (tc TLSConfig) config() (*tls.Config, *verifier) {
+	// The verification is done by VerifyConnection so the CA can be reloaded,
+	// the standard one is always disabled.
+	cfg := tls.Config{ServerName: tc.ServerName, InsecureSkipVerify: true}
+
+	if tc.CertFile != "" || tc.KeyFile != "" {
+		kp := newReloader(parseKeyPair, tc.CertFile, tc.KeyFile)
+
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return kp.get()
+		}
+	}
+
+	if tc.InsecureSkipVerify {
+		return &cfg, nil
+	}
+
+	var v verifier
+
+	if tc.CAFile != "" {
+		v.roots = newReloader(parseCertPool, tc.CAFile)
+	}
+
+	cfg.VerifyConnection = v.verifyConnection(tc.ServerName)
+
+	return &cfg, &v
+}
@@ -0,0 +1,48 @@
+package cqlutil
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostDialer(t *testing.T) {
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	defer srv.Close()
+
+	var (
+		ca = filepath.Join(t.TempDir(), "ca.pem")
+		cc gocql.ClusterConfig
+
+		_, port, _ = net.SplitHostPort(srv.Listener.Addr().String())
+	)
+
+	require.NoError(
+		t,
+		os.WriteFile(
+			ca,
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+			0600,
+		),
+	)
+
+	TLSConfig{CAFile: ca}.apply(&cc)
+
+	// The certificate of the test server is issued for 127.0.0.1 but not for
+	// localhost.
+	conn, err := cc.Dialer.DialContext(context.Background(), "tcp", "127.0.0.1:"+port)
+	require.NoError(t, err)
+	conn.Close()
+
+	_, err = cc.Dialer.DialContext(context.Background(), "tcp", "localhost:"+port)
+	assert.Error(t, err)
+}
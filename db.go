@@ -2,11 +2,17 @@ package cql
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/upfluence/errors"
 )
 
-var ErrNoRows = errors.New("No rows found")
+var (
+	ErrNoRows              = errors.New("No rows found")
+	ErrMapScanNotSupported = errors.New("MapScan is not supported")
+	ErrClosed              = errors.New("DB is closed")
+)
 
 //go:generate stringer -type=BatchType
 type BatchType uint8
@@ -17,6 +23,30 @@ const (
 	CounterBatch
 )
 
+var batchKeywords = map[BatchType]string{
+	LoggedBatch:   "BEGIN BATCH",
+	UnloggedBatch: "BEGIN UNLOGGED BATCH",
+	CounterBatch:  "BEGIN COUNTER BATCH",
+}
+
+// BatchStatement writes the statements of a batch as a single one, it is the
+// form the middlewares report batches with.
+func BatchStatement(bt BatchType, stmts []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(batchKeywords[bt])
+
+	for _, stmt := range stmts {
+		sb.WriteByte(' ')
+		sb.WriteString(stmt)
+		sb.WriteByte(';')
+	}
+
+	sb.WriteString(" APPLY BATCH")
+
+	return sb.String()
+}
+
 type Option interface {
 	IsCQLOption()
 }
@@ -40,10 +70,42 @@ type WithConsistency Consistency
 
 func (WithConsistency) IsCQLOption() {}
 
+//go:generate stringer -type=SerialConsistency
+type SerialConsistency uint16
+
+const (
+	Serial      SerialConsistency = 0x08
+	LocalSerial SerialConsistency = 0x09
+)
+
+type WithSerialConsistency SerialConsistency
+
+func (WithSerialConsistency) IsCQLOption() {}
+
 type NamedQuery string
 
 func (nq NamedQuery) IsCQLOption() {}
 
+type WithIdempotency bool
+
+func (WithIdempotency) IsCQLOption() {}
+
+// Idempotent flags the statement as safe to be retried or speculatively
+// executed.
+var Idempotent = WithIdempotency(true)
+
+type WithTimeout time.Duration
+
+func (WithTimeout) IsCQLOption() {}
+
+type WithPageSize int
+
+func (WithPageSize) IsCQLOption() {}
+
+type WithPageState []byte
+
+func (WithPageState) IsCQLOption() {}
+
 type CASScanner interface {
 	ScanCAS(...interface{}) (bool, error)
 }
@@ -52,11 +114,76 @@ type Scanner interface {
 	Scan(...interface{}) error
 }
 
+type ColumnInfo struct {
+	Keyspace string
+	Table    string
+	Name     string
+	Type     string
+}
+
+// ColumnDescriber is implemented by the scanners and cursors able to describe
+// the columns returned by their query.
+type ColumnDescriber interface {
+	Columns() []ColumnInfo
+}
+
+func Columns(v interface{}) []ColumnInfo {
+	if cd, ok := v.(ColumnDescriber); ok {
+		return cd.Columns()
+	}
+
+	return nil
+}
+
+type MapScanner interface {
+	Scanner
+
+	MapScan(map[string]interface{}) error
+}
+
+func MapScan(sc Scanner, m map[string]interface{}) error {
+	if msc, ok := sc.(MapScanner); ok {
+		return msc.MapScan(m)
+	}
+
+	return ErrMapScanNotSupported
+}
+
 type Cursor interface {
 	Scan(...interface{}) bool
 	Close() error
 }
 
+type MapCursor interface {
+	Cursor
+
+	MapScan(map[string]interface{}) bool
+}
+
+func CursorMapScan(c Cursor, m map[string]interface{}) bool {
+	if mc, ok := c.(MapCursor); ok {
+		return mc.MapScan(m)
+	}
+
+	return false
+}
+
+// PagedCursor is implemented by cursors able to report the paging state to
+// give back through WithPageState to fetch the page following the current one.
+type PagedCursor interface {
+	Cursor
+
+	PageState() []byte
+}
+
+func CursorPageState(c Cursor) []byte {
+	if pc, ok := c.(PagedCursor); ok {
+		return pc.PageState()
+	}
+
+	return nil
+}
+
 type Batch interface {
 	Query(string, ...interface{})
 
@@ -74,6 +201,105 @@ type DB interface {
 	Batch(context.Context, BatchType, ...Option) Batch
 }
 
+// Closer is implemented by the DBs releasing resources on shutdown, the context
+// bounds the time spent waiting for the in flight operations to be over.
+type Closer interface {
+	Close(context.Context) error
+}
+
+func Close(ctx context.Context, db DB) error {
+	if c, ok := db.(Closer); ok {
+		return c.Close(ctx)
+	}
+
+	return nil
+}
+
+type HostStatus struct {
+	Address    string
+	DataCenter string
+	Rack       string
+	Up         bool
+}
+
+type Health struct {
+	Hosts           []HostStatus
+	SchemaAgreement bool
+}
+
+type Pinger interface {
+	Ping(context.Context) (Health, error)
+}
+
+const pingStmt = "SELECT release_version FROM system.local"
+
+// Ping checks the DB is able to serve queries, when it does not implement
+// Pinger a query against system.local is run and nothing else is reported.
+func Ping(ctx context.Context, db DB) (Health, error) {
+	if p, ok := db.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	var v string
+
+	return Health{}, db.QueryRow(ctx, pingStmt).Scan(&v)
+}
+
+type unsetValue struct{}
+
+// UnsetValue can be bound to leave a column untouched instead of writing a
+// null, it requires the native protocol v4 or later.
+var UnsetValue interface{} = unsetValue{}
+
+type ProtoVersioner interface {
+	ProtoVersion() int
+}
+
+// ProtoVersion returns the native protocol version used by the DB or 0 when
+// it is unknown.
+func ProtoVersion(db DB) int {
+	if pv, ok := db.(ProtoVersioner); ok {
+		return pv.ProtoVersion()
+	}
+
+	return 0
+}
+
 type MiddlewareFactory interface {
 	Wrap(DB) DB
 }
+
+// OptionValues turns options into values, so they can be read along the
+// values of an operation.
+func OptionValues(opts []Option) []interface{} {
+	vs := make([]interface{}, len(opts))
+
+	for i, opt := range opts {
+		vs[i] = opt
+	}
+
+	return vs
+}
+
+type errScanner struct{ error }
+
+func (es errScanner) Scan(...interface{}) error { return es.error }
+
+// ErrScanner returns a Scanner failing with err, for the DBs failing an
+// operation before running it.
+func ErrScanner(err error) Scanner { return errScanner{err} }
+
+type errCASScanner struct{ error }
+
+func (ecs errCASScanner) ScanCAS(...interface{}) (bool, error) {
+	return false, ecs.error
+}
+
+func ErrCASScanner(err error) CASScanner { return errCASScanner{err} }
+
+type errCursor struct{ error }
+
+func (ec errCursor) Scan(...interface{}) bool { return false }
+func (ec errCursor) Close() error             { return ec.error }
+
+func ErrCursor(err error) Cursor { return errCursor{err} }
@@ -0,0 +1,33 @@
+package cql
+
+import "github.com/upfluence/errors"
+
+var (
+	ErrReadTimeout   = errors.New("Read timeout")
+	ErrWriteTimeout  = errors.New("Write timeout")
+	ErrUnavailable   = errors.New("Not enough replicas available")
+	ErrOverloaded    = errors.New("Coordinator overloaded")
+	ErrAlreadyExists = errors.New("Keyspace or table already exists")
+	ErrInvalidQuery  = errors.New("Invalid query")
+	ErrUnauthorized  = errors.New("Unauthorized")
+)
+
+// Error ties an error returned by a backend to one of the error classes
+// defined above, errors.Is matches both the class and the original error.
+type Error struct {
+	Class error
+	Err   error
+}
+
+func (e *Error) Error() string   { return e.Err.Error() }
+func (e *Error) Unwrap() []error { return []error{e.Class, e.Err} }
+
+func ErrorClass(err error) error {
+	var e *Error
+
+	if errors.As(err, &e) {
+		return e.Class
+	}
+
+	return nil
+}
@@ -46,3 +46,108 @@ func TestMigrationIntegration(t *testing.T) {
 		assert.Equal(t, []byte("foo"), data)
 	})
 }
+
+func TestPagingIntegration(t *testing.T) {
+	cqltest.NewTestCase(
+		cqltest.WithMigratorFunc(func(db cql.DB) migration.Migrator {
+			return migration.NewMigrator(
+				db,
+				cqltest.StaticSource{
+					MigrationUp:   "CREATE TABLE IF NOT EXISTS bar(id int, pos int, PRIMARY KEY (id, pos))",
+					MigrationDown: "DROP TABLE bar",
+				},
+				migration.MigrationTable("paging_integration_migrations"),
+			)
+		}),
+	).Run(t, func(t *testing.T, db cql.DB) {
+		ctx := context.Background()
+
+		for i := 0; i < 5; i++ {
+			assert.NoError(
+				t,
+				db.Exec(ctx, "INSERT INTO bar(id, pos) VALUES (?, ?)", 1, i),
+			)
+		}
+
+		var (
+			state cql.WithPageState
+			pages [][]int
+		)
+
+		for {
+			var (
+				pos  int
+				page []int
+
+				cur = db.Query(
+					ctx,
+					"SELECT pos FROM bar WHERE id = ?",
+					1,
+					cql.WithPageSize(2),
+					state,
+				)
+			)
+
+			for cur.Scan(&pos) {
+				page = append(page, pos)
+			}
+
+			state = cql.CursorPageState(cur)
+
+			assert.NoError(t, cur.Close())
+
+			pages = append(pages, page)
+
+			if len(state) == 0 {
+				break
+			}
+		}
+
+		assert.Equal(t, [][]int{{0, 1}, {2, 3}, {4}}, pages)
+	})
+}
+
+func TestMapScanIntegration(t *testing.T) {
+	cqltest.NewTestCase(
+		cqltest.WithMigratorFunc(func(db cql.DB) migration.Migrator {
+			return migration.NewMigrator(
+				db,
+				cqltest.StaticSource{
+					MigrationUp:   "CREATE TABLE IF NOT EXISTS buz(id int PRIMARY KEY, name text)",
+					MigrationDown: "DROP TABLE buz",
+				},
+				migration.MigrationTable("map_scan_integration_migrations"),
+			)
+		}),
+	).Run(t, func(t *testing.T, db cql.DB) {
+		ctx := context.Background()
+
+		assert.NoError(
+			t,
+			db.Exec(ctx, "INSERT INTO buz(id, name) VALUES (?, ?)", 1, "foo"),
+		)
+
+		sc := db.QueryRow(ctx, "SELECT id, name FROM buz WHERE id = ?", 1)
+
+		var names []string
+
+		for _, c := range cql.Columns(sc) {
+			names = append(names, c.Name)
+		}
+
+		assert.Equal(t, []string{"id", "name"}, names)
+
+		m := map[string]interface{}{}
+
+		assert.NoError(t, cql.MapScan(sc, m))
+		assert.Equal(t, map[string]interface{}{"id": 1, "name": "foo"}, m)
+
+		cur := db.Query(ctx, "SELECT name FROM buz")
+		m = map[string]interface{}{}
+
+		assert.True(t, cql.CursorMapScan(cur, m))
+		assert.Equal(t, map[string]interface{}{"name": "foo"}, m)
+		assert.False(t, cql.CursorMapScan(cur, m))
+		assert.NoError(t, cur.Close())
+	})
+}
@@ -0,0 +1,83 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+type item[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Cache is a fixed size cache safe for concurrent use, the least recently
+// used entry is evicted to make room for a new one.
+type Cache[K comparable, V any] struct {
+	mu sync.Mutex
+
+	size  int
+	ll    *list.List
+	items map[K]*list.Element
+}
+
+func New[K comparable, V any](size int) *Cache[K, V] {
+	return &Cache[K, V]{size: size, ll: list.New(), items: make(map[K]*list.Element)}
+}
+
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[k]
+
+	if !ok {
+		var v V
+
+		return v, false
+	}
+
+	c.ll.MoveToFront(e)
+
+	return e.Value.(*item[K, V]).value, true
+}
+
+func (c *Cache[K, V]) Add(k K, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[k]; ok {
+		e.Value.(*item[K, V]).value = v
+		c.ll.MoveToFront(e)
+
+		return
+	}
+
+	c.items[k] = c.ll.PushFront(&item[K, V]{key: k, value: v})
+
+	if c.ll.Len() > c.size {
+		e := c.ll.Back()
+
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*item[K, V]).key)
+	}
+}
+
+// GetOrAdd returns the value cached for k, it is computed by fn and added
+// when missing.
+func (c *Cache[K, V]) GetOrAdd(k K, fn func(K) V) V {
+	if v, ok := c.Get(k); ok {
+		return v
+	}
+
+	v := fn(k)
+	c.Add(k, v)
+
+	return v
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
@@ -0,0 +1,30 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c := New[string, int](2)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	c.Add("c", 3)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	assert.Equal(t, 1, c.GetOrAdd("a", func(string) int { return 0 }))
+	assert.Equal(t, 4, c.GetOrAdd("d", func(string) int { return 4 }))
+
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+}
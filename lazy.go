@@ -0,0 +1,83 @@
+package cql
+
+// A QueryRow or an ExecCAS is only run once its scanner is used, so the
+// middlewares holding a resource for the time of an operation, such as a
+// limiter slot or a breaker probe, acquire it from LazyScanner and
+// LazyCASScanner: a scanner dropped without being used then holds nothing.
+
+type lazyScanner struct {
+	begin func() (Scanner, func(error), error)
+
+	sc   Scanner
+	done func(error)
+	err  error
+}
+
+// LazyScanner returns a Scanner calling begin on its first call to Columns,
+// Scan or MapScan. begin returns the scanner of the operation and the
+// function called with its outcome once the row is scanned.
+func LazyScanner(begin func() (Scanner, func(error), error)) Scanner {
+	return &lazyScanner{begin: begin}
+}
+
+func (ls *lazyScanner) init() error {
+	if ls.sc == nil && ls.err == nil {
+		ls.sc, ls.done, ls.err = ls.begin()
+	}
+
+	return ls.err
+}
+
+func (ls *lazyScanner) Columns() []ColumnInfo {
+	if ls.init() != nil {
+		return nil
+	}
+
+	return Columns(ls.sc)
+}
+
+func (ls *lazyScanner) scan(fn func(Scanner) error) error {
+	if err := ls.init(); err != nil {
+		return err
+	}
+
+	err := fn(ls.sc)
+
+	if done := ls.done; done != nil {
+		ls.done = nil
+		done(err)
+	}
+
+	return err
+}
+
+func (ls *lazyScanner) Scan(vs ...interface{}) error {
+	return ls.scan(func(sc Scanner) error { return sc.Scan(vs...) })
+}
+
+func (ls *lazyScanner) MapScan(m map[string]interface{}) error {
+	return ls.scan(func(sc Scanner) error { return MapScan(sc, m) })
+}
+
+type lazyCASScanner struct {
+	begin func() (CASScanner, func(bool, error), error)
+}
+
+// LazyCASScanner returns a CASScanner calling begin from ScanCAS, the
+// returned function is called with the outcome of the scan.
+func LazyCASScanner(begin func() (CASScanner, func(bool, error), error)) CASScanner {
+	return lazyCASScanner{begin: begin}
+}
+
+func (lcs lazyCASScanner) ScanCAS(vs ...interface{}) (bool, error) {
+	sc, done, err := lcs.begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := sc.ScanCAS(vs...)
+	done(ok, err)
+
+	return ok, err
+}
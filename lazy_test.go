@@ -0,0 +1,42 @@
+package cql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type columnScanner struct{ err error }
+
+func (cs columnScanner) Scan(...interface{}) error { return cs.err }
+
+func (cs columnScanner) Columns() []ColumnInfo {
+	return []ColumnInfo{{Name: "id"}}
+}
+
+func TestLazyScanner(t *testing.T) {
+	var (
+		begun int
+		errs  []error
+
+		boom = errors.New("boom")
+	)
+
+	sc := LazyScanner(func() (Scanner, func(error), error) {
+		begun++
+
+		return columnScanner{err: boom}, func(err error) { errs = append(errs, err) }, nil
+	})
+
+	assert.Equal(t, 0, begun)
+	assert.Equal(t, []ColumnInfo{{Name: "id"}}, Columns(sc))
+	assert.Equal(t, boom, sc.Scan())
+	assert.Equal(t, 1, begun)
+	assert.Equal(t, []error{boom}, errs)
+
+	sc = LazyScanner(func() (Scanner, func(error), error) { return nil, nil, boom })
+
+	assert.Nil(t, Columns(sc))
+	assert.Equal(t, boom, sc.Scan())
+}
@@ -0,0 +1,164 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+type State uint8
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	}
+
+	return "unknown"
+}
+
+type transition struct {
+	from, to State
+}
+
+type outcome uint8
+
+const (
+	success outcome = iota
+	failure
+	ignored
+)
+
+// ticket identifies an operation let through, probe is set for the ones let
+// through while half-open.
+type ticket struct {
+	generation uint64
+	probe      uint64
+}
+
+type breaker struct {
+	mu sync.Mutex
+
+	state      State
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	successes  int
+
+	lastProbe uint64
+	probes    map[uint64]time.Time
+}
+
+// allow returns the ticket of the operation, false means the breaker is open
+// and the operation should not be run.
+func (b *breaker) allow(f *factory) (ticket, []transition, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		ts  []transition
+		now = f.now()
+	)
+
+	if b.state == Open {
+		if now.Sub(b.openedAt) < f.openTimeout {
+			return ticket{}, nil, false
+		}
+
+		ts = append(ts, b.transition(HalfOpen, f))
+	}
+
+	if b.state != HalfOpen {
+		return ticket{generation: b.generation}, ts, true
+	}
+
+	// A probe never over, such as a cursor never closed, gives its slot
+	// back after the open timeout.
+	for id, t0 := range b.probes {
+		if now.Sub(t0) >= f.openTimeout {
+			delete(b.probes, id)
+		}
+	}
+
+	if len(b.probes) >= f.probes {
+		return ticket{}, ts, false
+	}
+
+	if b.probes == nil {
+		b.probes = make(map[uint64]time.Time)
+	}
+
+	b.lastProbe++
+	b.probes[b.lastProbe] = now
+
+	return ticket{generation: b.generation, probe: b.lastProbe}, ts, true
+}
+
+// done accounts the outcome of an operation, an ignored operation frees its
+// probe slot but leaves the counters untouched.
+func (b *breaker) done(f *factory, t ticket, o outcome) []transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t.generation != b.generation {
+		return nil
+	}
+
+	switch b.state {
+	case Closed:
+		switch o {
+		case success:
+			b.failures = 0
+		case failure:
+			b.failures++
+
+			if b.failures >= f.threshold {
+				return []transition{b.transition(Open, f)}
+			}
+		}
+	case HalfOpen:
+		if _, ok := b.probes[t.probe]; !ok {
+			return nil
+		}
+
+		delete(b.probes, t.probe)
+
+		switch o {
+		case success:
+			b.successes++
+
+			if b.successes >= f.probes {
+				return []transition{b.transition(Closed, f)}
+			}
+		case failure:
+			return []transition{b.transition(Open, f)}
+		}
+	}
+
+	return nil
+}
+
+func (b *breaker) transition(s State, f *factory) transition {
+	t := transition{from: b.state, to: s}
+
+	b.state = s
+	b.generation++
+	b.failures = 0
+	b.probes = nil
+	b.successes = 0
+
+	if s == Open {
+		b.openedAt = f.now()
+	}
+
+	return t
+}
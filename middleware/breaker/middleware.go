@@ -0,0 +1,330 @@
+package breaker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqlfingerprint"
+)
+
+var ErrOpen = errors.New("Circuit breaker is open")
+
+// OpenError is returned without running the operation while the breaker of
+// the given key is open.
+type OpenError struct {
+	Key string
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrOpen.Error(), e.Key)
+}
+
+func (e *OpenError) Unwrap() error { return ErrOpen }
+
+// KeyFunc picks the breaker an operation is accounted on.
+type KeyFunc func(stmt string, vs []interface{}) string
+
+// PerQuery keys the operations by their cql.NamedQuery or, when they are not
+// named, by the fingerprint of their statement.
+func PerQuery(stmt string, vs []interface{}) string {
+	if nq, ok := cql.LookupNamedQuery(vs); ok {
+		return nq
+	}
+
+	return cqlfingerprint.Fingerprint(stmt)
+}
+
+// PerCluster accounts every operation on a single breaker.
+func PerCluster(name string) KeyFunc {
+	return func(string, []interface{}) string { return name }
+}
+
+// IsFailure is the default failure rule, the errors caused by the query
+// itself rather than by the cluster health are not accounted. The errors which
+// are not failures are ignored, they do not count as successes either.
+func IsFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, e := range []error{
+		cql.ErrNoRows,
+		cql.ErrInvalidQuery,
+		cql.ErrAlreadyExists,
+		cql.ErrUnauthorized,
+		context.Canceled,
+		ErrOpen,
+	} {
+		if errors.Is(err, e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type Option func(*factory)
+
+// WithThreshold sets the number of consecutive failures opening the breaker.
+func WithThreshold(n int) Option {
+	return func(f *factory) { f.threshold = n }
+}
+
+// WithOpenTimeout sets for how long the breaker stays open before letting
+// probes through, a probe not over after as long gives its slot back.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(f *factory) { f.openTimeout = d }
+}
+
+// WithProbes sets the number of operations let through while half-open, the
+// breaker closes once they all succeed.
+func WithProbes(n int) Option {
+	return func(f *factory) { f.probes = n }
+}
+
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(f *factory) { f.key = fn }
+}
+
+func WithFailureRule(fn func(error) bool) Option {
+	return func(f *factory) { f.isFailure = fn }
+}
+
+// WithStateChange registers a callback called every time a breaker changes
+// state.
+func WithStateChange(fn func(key string, from, to State)) Option {
+	return func(f *factory) { f.onChange = append(f.onChange, fn) }
+}
+
+type factory struct {
+	threshold   int
+	openTimeout time.Duration
+	probes      int
+	key         KeyFunc
+	isFailure   func(error) bool
+	onChange    []func(string, State, State)
+
+	now func() time.Time
+}
+
+func NewFactory(opts ...Option) cql.MiddlewareFactory {
+	f := factory{
+		threshold:   5,
+		openTimeout: 30 * time.Second,
+		probes:      1,
+		key:         PerQuery,
+		isFailure:   IsFailure,
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return &f
+}
+
+func (f *factory) outcome(err error) outcome {
+	switch {
+	case err == nil:
+		return success
+	case f.isFailure(err):
+		return failure
+	}
+
+	return ignored
+}
+
+func (f *factory) Wrap(db cql.DB) cql.DB {
+	return &DB{db: db, f: f}
+}
+
+type DB struct {
+	db cql.DB
+	f  *factory
+
+	breakers sync.Map
+}
+
+func (db *DB) Unwrap() cql.DB { return db.db }
+
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
+}
+
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+// States returns the state of every breaker created so far.
+func (db *DB) States() map[string]State {
+	ss := make(map[string]State)
+
+	db.breakers.Range(func(k, v interface{}) bool {
+		b := v.(*breaker)
+
+		b.mu.Lock()
+		ss[k.(string)] = b.state
+		b.mu.Unlock()
+
+		return true
+	})
+
+	return ss
+}
+
+func (db *DB) notify(key string, ts []transition) {
+	for _, t := range ts {
+		for _, fn := range db.f.onChange {
+			fn(key, t.from, t.to)
+		}
+	}
+}
+
+// begin returns the function to call with the outcome of the operation or
+// an *OpenError if the operation should not be run.
+func (db *DB) begin(ctx context.Context, stmt string, vs []interface{}) (func(error), error) {
+	var (
+		key  = db.f.key(stmt, cql.WithContextOptions(ctx, vs))
+		v, _ = db.breakers.LoadOrStore(key, &breaker{})
+		b    = v.(*breaker)
+	)
+
+	t, ts, ok := b.allow(db.f)
+
+	db.notify(key, ts)
+
+	if !ok {
+		return nil, &OpenError{Key: key}
+	}
+
+	return func(err error) {
+		db.notify(key, b.done(db.f, t, db.f.outcome(err)))
+	}, nil
+}
+
+func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	done, err := db.begin(ctx, stmt, vs)
+
+	if err != nil {
+		return err
+	}
+
+	err = db.db.Exec(ctx, stmt, vs...)
+	done(err)
+
+	return err
+}
+
+func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
+	return cql.LazyCASScanner(func() (cql.CASScanner, func(bool, error), error) {
+		done, err := db.begin(ctx, stmt, vs)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sc := db.db.ExecCAS(ctx, stmt, vs...)
+
+		return sc, func(_ bool, err error) { done(err) }, nil
+	})
+}
+
+func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
+	return cql.LazyScanner(func() (cql.Scanner, func(error), error) {
+		done, err := db.begin(ctx, stmt, vs)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return db.db.QueryRow(ctx, stmt, vs...), done, nil
+	})
+}
+
+type cursor struct {
+	cql.Cursor
+
+	done func(error)
+}
+
+func (c cursor) MapScan(m map[string]interface{}) bool {
+	return cql.CursorMapScan(c.Cursor, m)
+}
+
+func (c cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
+func (c cursor) Close() error {
+	err := c.Cursor.Close()
+
+	c.done(err)
+
+	return err
+}
+
+func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
+	done, err := db.begin(ctx, stmt, vs)
+
+	if err != nil {
+		return cql.ErrCursor(err)
+	}
+
+	return cursor{Cursor: db.db.Query(ctx, stmt, vs...), done: done}
+}
+
+type batch struct {
+	cql.Batch
+
+	db   *DB
+	ctx  context.Context
+	opts []interface{}
+}
+
+func (b *batch) Exec() error {
+	done, err := b.db.begin(b.ctx, "BATCH", b.opts)
+
+	if err != nil {
+		return err
+	}
+
+	err = b.Batch.Exec()
+	done(err)
+
+	return err
+}
+
+func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
+	done, err := b.db.begin(b.ctx, "BATCH", b.opts)
+
+	if err != nil {
+		return false, nil, err
+	}
+
+	ok, cur, err := b.Batch.ExecCAS()
+	done(err)
+
+	return ok, cur, err
+}
+
+// Batch accounts the batches on the key computed for a "BATCH" statement,
+// they share a single breaker unless they are given a cql.NamedQuery option.
+func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	vs := cql.OptionValues(opts)
+
+	return &batch{Batch: db.db.Batch(ctx, bt, opts...), db: db, ctx: ctx, opts: vs}
+}
@@ -0,0 +1,176 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
+
+var errUnavailable = &cql.Error{Class: cql.ErrUnavailable, Err: errors.New("boom")}
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func TestBreaker(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		c     = clock{t: time.Unix(0, 0)}
+		mdb   = cqltest.MockDB{Err: errUnavailable}
+		trans []State
+
+		f = NewFactory(
+			WithThreshold(2),
+			WithOpenTimeout(time.Second),
+			WithStateChange(func(key string, _, to State) {
+				if key == "insert_user" {
+					trans = append(trans, to)
+				}
+			}),
+		).(*factory)
+	)
+
+	f.now = c.now
+
+	db := f.Wrap(&mdb).(*DB)
+
+	exec := func(stmt string, vs ...interface{}) error {
+		return db.Exec(ctx, stmt, vs...)
+	}
+
+	nq := cql.NamedQuery("insert_user")
+
+	assert.Error(t, exec("INSERT", nq))
+	assert.Error(t, exec("INSERT", nq))
+	assert.Equal(t, 2, mdb.Calls())
+
+	var oe *OpenError
+
+	err := exec("INSERT", nq)
+	assert.ErrorAs(t, err, &oe)
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.Equal(t, "insert_user", oe.Key)
+	assert.Equal(t, 2, mdb.Calls())
+
+	// The statements which are not named use their fingerprint.
+	assert.Error(t, exec("SELECT * FROM users WHERE id = 1"))
+	assert.Error(t, exec("SELECT * FROM users WHERE id = 2"))
+	assert.ErrorIs(t, exec("SELECT * FROM users WHERE id = 3"), ErrOpen)
+	assert.Equal(
+		t,
+		map[string]State{
+			"insert_user":                      Open,
+			"SELECT * FROM users WHERE id = ?": Open,
+		},
+		db.States(),
+	)
+
+	mdb.ResetCalls()
+	c.t = c.t.Add(time.Second)
+
+	assert.Error(t, exec("INSERT", nq))
+	assert.ErrorIs(t, exec("INSERT", nq), ErrOpen)
+	assert.Equal(t, 1, mdb.Calls())
+
+	mdb.Err = nil
+	c.t = c.t.Add(time.Second)
+
+	assert.NoError(t, exec("INSERT", nq))
+	assert.NoError(t, exec("INSERT", nq))
+
+	assert.Equal(t, []State{Open, HalfOpen, Open, HalfOpen, Closed}, trans)
+}
+
+func TestIgnoredOutcome(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = clock{t: time.Unix(0, 0)}
+		mdb = cqltest.MockDB{
+			Errs: []error{
+				errUnavailable,
+				context.Canceled,
+				errUnavailable,
+				context.Canceled,
+				nil,
+			},
+		}
+
+		f = NewFactory(WithThreshold(2), WithOpenTimeout(time.Second)).(*factory)
+	)
+
+	f.now = c.now
+
+	db := f.Wrap(&mdb).(*DB)
+
+	// The ignored errors do not reset the consecutive failures.
+	assert.Error(t, db.Exec(ctx, "INSERT"))
+	assert.ErrorIs(t, db.Exec(ctx, "INSERT"), context.Canceled)
+	assert.Error(t, db.Exec(ctx, "INSERT"))
+	assert.Equal(t, map[string]State{"INSERT": Open}, db.States())
+
+	c.t = c.t.Add(time.Second)
+
+	// A canceled probe frees its slot but does not close the breaker.
+	assert.ErrorIs(t, db.Exec(ctx, "INSERT"), context.Canceled)
+	assert.Equal(t, map[string]State{"INSERT": HalfOpen}, db.States())
+
+	assert.NoError(t, db.Exec(ctx, "INSERT"))
+	assert.Equal(t, map[string]State{"INSERT": Closed}, db.States())
+}
+
+func TestAbandonedProbe(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = clock{t: time.Unix(0, 0)}
+		mdb = cqltest.MockDB{Errs: []error{errUnavailable}}
+
+		f = NewFactory(WithThreshold(1), WithOpenTimeout(time.Second)).(*factory)
+	)
+
+	f.now = c.now
+
+	db := f.Wrap(&mdb).(*DB)
+
+	assert.Error(t, db.Exec(ctx, "SELECT"))
+
+	c.t = c.t.Add(time.Second)
+
+	// A scanner never scanned does not take the probe.
+	db.QueryRow(ctx, "SELECT")
+
+	// A cursor never closed holds the probe until the open timeout.
+	db.Query(ctx, "SELECT")
+	assert.ErrorIs(t, db.QueryRow(ctx, "SELECT").Scan(), ErrOpen)
+
+	c.t = c.t.Add(time.Second)
+
+	assert.NoError(t, db.QueryRow(ctx, "SELECT").Scan())
+	assert.Equal(t, map[string]State{"SELECT": Closed}, db.States())
+}
+
+func TestColumns(t *testing.T) {
+	var (
+		cis = []cql.ColumnInfo{{Name: "id"}}
+		mdb = cqltest.MockDB{ColumnInfos: cis}
+		db  = NewFactory().Wrap(&mdb)
+		sc  = db.QueryRow(context.Background(), "SELECT id FROM users")
+	)
+
+	assert.Equal(t, cis, cql.Columns(sc))
+	assert.NoError(t, sc.Scan())
+	assert.Equal(t, 1, mdb.Calls())
+}
+
+func TestIsFailure(t *testing.T) {
+	assert.False(t, IsFailure(nil))
+	assert.False(t, IsFailure(cql.ErrNoRows))
+	assert.False(t, IsFailure(&cql.Error{Class: cql.ErrInvalidQuery, Err: errors.New("bad")}))
+	assert.True(t, IsFailure(&cql.Error{Class: cql.ErrReadTimeout, Err: errors.New("read")}))
+	assert.True(t, IsFailure(context.DeadlineExceeded))
+}
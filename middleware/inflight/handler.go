@@ -0,0 +1,80 @@
+package inflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+var ErrNotInChain = errors.New("Inflight middleware is not part of the chain")
+
+type handler struct {
+	db *DB
+}
+
+// NewHandler returns an http.Handler listing the operations in flight with
+// their caller stack, as plain text or as JSON when the format query
+// parameter is set to json. A POST request with an id parameter cancels the
+// context of the given operation. It fails with ErrNotInChain if the
+// inflight middleware is not part of the chain of db.
+func NewHandler(db cql.DB) (http.Handler, error) {
+	var idb *DB
+
+	if !cql.As(db, &idb) {
+		return nil, ErrNotInChain
+	}
+
+	return &handler{db: idb}, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.list(w, r)
+	case http.MethodPost:
+		h.cancel(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	ops := h.db.Operations()
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+
+		json.NewEncoder(w).Encode(ops)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	fmt.Fprintf(w, "%d operation(s) in flight\n", len(ops))
+
+	for _, op := range ops {
+		fmt.Fprintf(w, "\n%s", op)
+	}
+}
+
+func (h *handler) cancel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+
+	if err != nil {
+		http.Error(w, "invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	if !h.db.Cancel(id) {
+		http.Error(w, "operation not in flight", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,184 @@
+package inflight
+
+import (
+	"context"
+	"time"
+
+	"github.com/upfluence/cql"
+)
+
+type factory struct{}
+
+// NewFactory returns a middleware factory tracking the operations in flight,
+// they are listed by DB.Operations and by the handler returned by
+// NewHandler.
+func NewFactory() cql.MiddlewareFactory { return factory{} }
+
+func (factory) Wrap(db cql.DB) cql.DB { return &DB{db: db} }
+
+type DB struct {
+	db cql.DB
+
+	r registry
+}
+
+func (db *DB) Unwrap() cql.DB { return db.db }
+
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
+}
+
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+// Operations returns the operations in flight, the oldest first.
+func (db *DB) Operations() []Operation { return db.r.list() }
+
+// Cancel cancels the context of the operation, false means the operation is
+// not in flight anymore.
+func (db *DB) Cancel(id uint64) bool { return db.r.cancel(id) }
+
+func namedQuery(ctx context.Context, vs []interface{}) string {
+	nq, _ := cql.LookupNamedQuery(cql.WithContextOptions(ctx, vs))
+
+	return nq
+}
+
+// begin registers the operation and returns its cancelable context along the
+// function unregistering it, depth is the number of frames of the middleware
+// between begin and its caller, they are left out of the stack.
+func (db *DB) begin(ctx context.Context, depth int, op OpType, stmt string, vs []interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return ctx, db.r.register(
+		&operation{
+			Operation: Operation{
+				Op:        op,
+				Statement: stmt,
+				Query:     namedQuery(ctx, vs),
+				StartedAt: time.Now(),
+			},
+			pcs:    callers(depth + 2),
+			cancel: cancel,
+		},
+	)
+}
+
+func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	ctx, done := db.begin(ctx, 1, Exec, stmt, vs)
+	defer done()
+
+	return db.db.Exec(ctx, stmt, vs...)
+}
+
+func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
+	return cql.LazyCASScanner(func() (cql.CASScanner, func(bool, error), error) {
+		ctx, done := db.begin(ctx, 1, ExecCAS, stmt, vs)
+		sc := db.db.ExecCAS(ctx, stmt, vs...)
+
+		return sc, func(bool, error) { done() }, nil
+	})
+}
+
+func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
+	return cql.LazyScanner(func() (cql.Scanner, func(error), error) {
+		ctx, done := db.begin(ctx, 1, QueryRow, stmt, vs)
+
+		return db.db.QueryRow(ctx, stmt, vs...), func(error) { done() }, nil
+	})
+}
+
+type cursor struct {
+	cql.Cursor
+
+	done func()
+}
+
+func (c cursor) MapScan(m map[string]interface{}) bool {
+	return cql.CursorMapScan(c.Cursor, m)
+}
+
+func (c cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
+func (c cursor) Close() error {
+	defer c.done()
+
+	return c.Cursor.Close()
+}
+
+// Query keeps the operation registered until the cursor is closed.
+func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
+	ctx, done := db.begin(ctx, 1, Query, stmt, vs)
+
+	return cursor{Cursor: db.db.Query(ctx, stmt, vs...), done: done}
+}
+
+type batch struct {
+	db  *DB
+	ctx context.Context
+	bt  cql.BatchType
+
+	opts  []cql.Option
+	stmts []string
+	vs    [][]interface{}
+}
+
+func (b *batch) Query(stmt string, vs ...interface{}) {
+	b.stmts = append(b.stmts, stmt)
+	b.vs = append(b.vs, vs)
+}
+
+// build registers the batch and builds the underlying one with the context
+// of the operation, so it can be canceled.
+func (b *batch) build() (cql.Batch, func()) {
+	ctx, done := b.db.begin(
+		b.ctx,
+		2,
+		Batch,
+		cql.BatchStatement(b.bt, b.stmts),
+		cql.OptionValues(b.opts),
+	)
+
+	cb := b.db.db.Batch(ctx, b.bt, b.opts...)
+
+	for i, stmt := range b.stmts {
+		cb.Query(stmt, b.vs[i]...)
+	}
+
+	return cb, done
+}
+
+func (b *batch) Exec() error {
+	cb, done := b.build()
+	defer done()
+
+	return cb.Exec()
+}
+
+// ExecCAS keeps the batch registered until the cursor is closed.
+func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
+	cb, done := b.build()
+	ok, cur, err := cb.ExecCAS()
+
+	if err != nil || cur == nil {
+		done()
+		return ok, cur, err
+	}
+
+	return ok, cursor{Cursor: cur, done: done}, nil
+}
+
+func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	return &batch{db: db, ctx: ctx, bt: bt, opts: opts}
+}
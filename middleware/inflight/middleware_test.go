@@ -0,0 +1,197 @@
+package inflight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
+
+func TestInflight(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		mdb     = cqltest.MockDB{
+			ExecFunc: func(ctx context.Context, _ string, _ ...interface{}) error {
+				close(started)
+				<-ctx.Done()
+
+				return ctx.Err()
+			},
+		}
+		db     = cql.Chain(NewFactory()).Wrap(&mdb)
+		h, err = NewHandler(db)
+
+		idb  *DB
+		errc = make(chan error, 1)
+	)
+
+	require.NoError(t, err)
+	require.True(t, cql.As(db, &idb))
+
+	go func() {
+		errc <- db.Exec(
+			context.Background(),
+			"DELETE FROM users WHERE id = ?",
+			1,
+			cql.NamedQuery("delete_user"),
+		)
+	}()
+
+	<-started
+
+	cur := db.Query(context.Background(), "SELECT * FROM users")
+
+	ops := idb.Operations()
+
+	require.Len(t, ops, 2)
+	assert.Equal(t, Exec, ops[0].Op)
+	assert.Equal(t, "DELETE FROM users WHERE id = ?", ops[0].Statement)
+	assert.Equal(t, "delete_user", ops[0].Query)
+	assert.NotEmpty(t, ops[0].Stack)
+	assert.Equal(t, Query, ops[1].Op)
+	assert.Contains(t, ops[1].Stack[0], "TestInflight")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "2 operation(s) in flight")
+	assert.Contains(t, w.Body.String(), "[delete_user]")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+
+	var res []Operation
+
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	assert.Len(t, res, 2)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("id=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.ErrorIs(t, <-errc, context.Canceled)
+
+	require.NoError(t, cur.Close())
+	assert.Empty(t, idb.Operations())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/?id=1", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestScannerNotScanned(t *testing.T) {
+	var (
+		ctx = context.Background()
+		cis = []cql.ColumnInfo{{Name: "id"}}
+		db  = NewFactory().Wrap(&cqltest.MockDB{ColumnInfos: cis}).(*DB)
+	)
+
+	sc := db.QueryRow(ctx, "SELECT id FROM users")
+	db.ExecCAS(ctx, "UPDATE users SET name = ? IF EXISTS", "foo")
+
+	assert.Empty(t, db.Operations())
+
+	// The operation is registered from Columns until the row is scanned.
+	assert.Equal(t, cis, cql.Columns(sc))
+
+	ops := db.Operations()
+
+	require.Len(t, ops, 1)
+	assert.Equal(t, QueryRow, ops[0].Op)
+	assert.Contains(t, ops[0].Stack[0], "TestScannerNotScanned")
+
+	assert.NoError(t, sc.Scan())
+	assert.Empty(t, db.Operations())
+}
+
+type inspectBatch struct {
+	cql.Batch
+
+	fn func()
+}
+
+func (b inspectBatch) Exec() error {
+	b.fn()
+
+	return b.Batch.Exec()
+}
+
+func (b inspectBatch) ExecCAS() (bool, cql.Cursor, error) {
+	return true, &cqltest.MockCursor{Rows: 1}, nil
+}
+
+type inspectDB struct {
+	*cqltest.MockDB
+
+	fn func()
+}
+
+func (db inspectDB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	return inspectBatch{Batch: db.MockDB.Batch(ctx, bt, opts...), fn: db.fn}
+}
+
+func TestBatch(t *testing.T) {
+	var (
+		ops []Operation
+		idb *DB
+	)
+
+	idb = NewFactory().Wrap(
+		inspectDB{
+			MockDB: &cqltest.MockDB{},
+			fn:     func() { ops = idb.Operations() },
+		},
+	).(*DB)
+
+	b := idb.Batch(context.Background(), cql.UnloggedBatch)
+	b.Query("INSERT INTO users(id) VALUES (?)", 1)
+	b.Query("INSERT INTO users(id) VALUES (?)", 2)
+
+	require.NoError(t, b.Exec())
+	require.Len(t, ops, 1)
+	assert.Equal(t, Batch, ops[0].Op)
+	assert.Equal(
+		t,
+		"BEGIN UNLOGGED BATCH INSERT INTO users(id) VALUES (?); INSERT INTO users(id) VALUES (?); APPLY BATCH",
+		ops[0].Statement,
+	)
+	assert.Contains(t, ops[0].Stack[0], "TestBatch")
+	assert.Empty(t, idb.Operations())
+}
+
+func TestBatchCASCursor(t *testing.T) {
+	idb := NewFactory().Wrap(inspectDB{MockDB: &cqltest.MockDB{}}).(*DB)
+
+	b := idb.Batch(context.Background(), cql.LoggedBatch)
+	b.Query("UPDATE users SET name = ? WHERE id = ? IF EXISTS", "foo", 1)
+
+	ok, cur, err := b.ExecCAS()
+
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, idb.Operations(), 1)
+
+	for cur.Scan() {
+	}
+
+	require.NoError(t, cur.Close())
+	assert.Empty(t, idb.Operations())
+}
+
+func TestNewHandlerMissing(t *testing.T) {
+	_, err := NewHandler(&cqltest.MockDB{})
+	assert.ErrorIs(t, err, ErrNotInChain)
+}
@@ -0,0 +1,172 @@
+package inflight
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxStackDepth = 32
+
+	cqlPackagePrefix = "github.com/upfluence/cql."
+)
+
+type OpType string
+
+const (
+	Exec     OpType = "Exec"
+	ExecCAS  OpType = "ExecCAS"
+	QueryRow OpType = "QueryRow"
+	Query    OpType = "Query"
+	Batch    OpType = "Batch"
+)
+
+// Operation describes an operation in flight.
+type Operation struct {
+	ID        uint64    `json:"id"`
+	Op        OpType    `json:"op"`
+	Statement string    `json:"statement"`
+	Query     string    `json:"query,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Stack     []string  `json:"stack"`
+}
+
+type operation struct {
+	Operation
+
+	pcs    []uintptr
+	cancel context.CancelFunc
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+
+	return pcs[:runtime.Callers(skip+1, pcs)]
+}
+
+// formatStack formats the frames, the leading ones of the cql package are the
+// lazy scanners running the operation on behalf of the caller and are left
+// out.
+func formatStack(pcs []uintptr) []string {
+	var (
+		ss []string
+		fs = runtime.CallersFrames(pcs)
+	)
+
+	for {
+		f, more := fs.Next()
+
+		if len(ss) == 0 && strings.HasPrefix(f.Function, cqlPackagePrefix) {
+			if !more {
+				return ss
+			}
+
+			continue
+		}
+
+		if f.Function != "" {
+			ss = append(ss, fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line))
+		}
+
+		if !more {
+			return ss
+		}
+	}
+}
+
+type registry struct {
+	mu     sync.Mutex
+	nextID uint64
+	ops    map[uint64]*operation
+}
+
+func (r *registry) register(op *operation) func() {
+	r.mu.Lock()
+
+	r.nextID++
+	op.ID = r.nextID
+
+	if r.ops == nil {
+		r.ops = make(map[uint64]*operation)
+	}
+
+	r.ops[op.ID] = op
+
+	r.mu.Unlock()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.ops, op.ID)
+			r.mu.Unlock()
+
+			op.cancel()
+		})
+	}
+}
+
+func (r *registry) list() []Operation {
+	r.mu.Lock()
+
+	ops := make([]*operation, 0, len(r.ops))
+
+	for _, op := range r.ops {
+		ops = append(ops, op)
+	}
+
+	r.mu.Unlock()
+
+	res := make([]Operation, len(ops))
+
+	for i, op := range ops {
+		res[i] = op.Operation
+		res[i].Stack = formatStack(op.pcs)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+
+	return res
+}
+
+func (r *registry) cancel(id uint64) bool {
+	r.mu.Lock()
+	op, ok := r.ops[id]
+	r.mu.Unlock()
+
+	if ok {
+		op.cancel()
+	}
+
+	return ok
+}
+
+func (o Operation) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(
+		&b,
+		"#%d %s for %s",
+		o.ID,
+		o.Op,
+		time.Since(o.StartedAt).Round(time.Millisecond),
+	)
+
+	if o.Query != "" {
+		fmt.Fprintf(&b, " [%s]", o.Query)
+	}
+
+	fmt.Fprintf(&b, "\n%s\n", o.Statement)
+
+	for _, s := range o.Stack {
+		fmt.Fprintf(&b, "%s\n", s)
+	}
+
+	return b.String()
+}
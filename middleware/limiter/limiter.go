@@ -0,0 +1,112 @@
+package limiter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limit describes the limits applied to the operations sharing a key, a zero
+// value field disables the matching limit.
+type Limit struct {
+	// MaxInflight caps the number of operations running concurrently.
+	MaxInflight int
+
+	// Rate is the number of operations allowed per second and Burst the size
+	// of the token bucket, it defaults to the rate rounded up.
+	Rate  float64
+	Burst int
+}
+
+type limiter struct {
+	sem chan struct{}
+	rl  *rate.Limiter
+}
+
+func newLimiter(l Limit) *limiter {
+	var lim limiter
+
+	if l.MaxInflight > 0 {
+		lim.sem = make(chan struct{}, l.MaxInflight)
+	}
+
+	if l.Rate > 0 {
+		b := l.Burst
+
+		if b <= 0 {
+			b = int(math.Ceil(l.Rate))
+		}
+
+		lim.rl = rate.NewLimiter(rate.Limit(l.Rate), b)
+	}
+
+	return &lim
+}
+
+// acquire waits for an in flight slot then for a token, the returned
+// function releases the slot. The slot is taken first so a wait canceled
+// before getting it leaves the tokens untouched.
+func (l *limiter) acquire(ctx context.Context) (func(), time.Duration, error) {
+	t0 := time.Now()
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	release := func() {}
+
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, time.Since(t0), ctx.Err()
+		}
+
+		var once sync.Once
+
+		release = func() { once.Do(func() { <-l.sem }) }
+	}
+
+	if l.rl != nil {
+		if err := l.wait(ctx); err != nil {
+			release()
+
+			return nil, time.Since(t0), err
+		}
+	}
+
+	return release, time.Since(t0), nil
+}
+
+// wait reserves a token and waits for it, the reservation is canceled when
+// the context is done first. It fails early when the token would only be
+// available after the context deadline, reporting the deadline as exceeded.
+func (l *limiter) wait(ctx context.Context) error {
+	r := l.rl.Reserve()
+	d := r.Delay()
+
+	if d == 0 {
+		return nil
+	}
+
+	if dl, ok := ctx.Deadline(); ok && time.Now().Add(d).After(dl) {
+		r.Cancel()
+
+		return context.DeadlineExceeded
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+
+		return ctx.Err()
+	}
+}
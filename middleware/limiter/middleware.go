@@ -0,0 +1,334 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/internal/lru"
+)
+
+type OpType string
+
+const (
+	Exec     OpType = "Exec"
+	ExecCAS  OpType = "ExecCAS"
+	QueryRow OpType = "QueryRow"
+	Query    OpType = "Query"
+	Batch    OpType = "Batch"
+
+	globalKey = "global"
+
+	// defaultKeyLabel labels the wait of the keys given the default limit.
+	defaultKeyLabel = "default"
+
+	defaultMaxKeys = 1024
+)
+
+// KeyFunc picks the limits applied to an operation.
+type KeyFunc func(ctx context.Context, op OpType, stmt string, vs []interface{}) string
+
+func Global(context.Context, OpType, string, []interface{}) string {
+	return globalKey
+}
+
+func ByOpType(_ context.Context, op OpType, _ string, _ []interface{}) string {
+	return string(op)
+}
+
+// ByNamedQuery keys the operations by their cql.NamedQuery, the operations
+// which are not named share the empty key.
+func ByNamedQuery(ctx context.Context, _ OpType, _ string, vs []interface{}) string {
+	nq, _ := cql.LookupNamedQuery(cql.WithContextOptions(ctx, vs))
+
+	return nq
+}
+
+// ByTag keys the operations by the value of the context tag k, see
+// cql.ContextWithTags.
+func ByTag(k string) KeyFunc {
+	return func(ctx context.Context, _ OpType, _ string, _ []interface{}) string {
+		v, _ := cql.ContextTag(ctx, k)
+
+		return v
+	}
+}
+
+// ByContextValue keys the operations by the value stored in their context
+// under k.
+func ByContextValue(k interface{}) KeyFunc {
+	return func(ctx context.Context, _ OpType, _ string, _ []interface{}) string {
+		switch v := ctx.Value(k).(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+}
+
+type Option func(*factory)
+
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(f *factory) { f.key = fn }
+}
+
+// WithLimit sets the limits of the operations matching the key.
+func WithLimit(key string, l Limit) Option {
+	return func(f *factory) { f.limits[key] = l }
+}
+
+// WithDefaultLimit sets the limits of the keys not given a dedicated one
+// with WithLimit, every key gets its own limiter. By default the operations
+// of such keys are not limited.
+func WithDefaultLimit(l Limit) Option {
+	return func(f *factory) { f.defaultLimit = &l }
+}
+
+// WithMaxKeys caps the number of limiters kept for the keys given the
+// default limit, the least recently used one is dropped beyond it. The
+// operations of a dropped key still running are not accounted by its next
+// limiter. It defaults to 1024.
+func WithMaxKeys(n int) Option {
+	return func(f *factory) { f.maxKeys = n }
+}
+
+// WithRegisterer records the time spent waiting for the limiters on reg, the
+// keys given the default limit share the "default" key label.
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return func(f *factory) {
+		f.wait = register(
+			reg,
+			prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Namespace: "cql",
+					Subsystem: "limiter",
+					Name:      "wait_duration_seconds",
+					Help:      "Time spent waiting for the limiter",
+					Buckets:   prometheus.DefBuckets,
+				},
+				[]string{"key", "op", "outcome"},
+			),
+		)
+	}
+}
+
+type factory struct {
+	key          KeyFunc
+	limits       map[string]Limit
+	defaultLimit *Limit
+	maxKeys      int
+	wait         *prometheus.HistogramVec
+}
+
+func NewFactory(opts ...Option) cql.MiddlewareFactory {
+	f := factory{
+		key:     Global,
+		limits:  make(map[string]Limit),
+		maxKeys: defaultMaxKeys,
+	}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return &f
+}
+
+func (f *factory) Wrap(db cql.DB) cql.DB {
+	return &DB{db: db, f: f, defaults: lru.New[string, *limiter](f.maxKeys)}
+}
+
+type DB struct {
+	db cql.DB
+	f  *factory
+
+	mu       sync.Mutex
+	limiters map[string]*limiter
+	defaults *lru.Cache[string, *limiter]
+}
+
+func (db *DB) Unwrap() cql.DB { return db.db }
+
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
+}
+
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+// limiter returns the limiter of the key along its label, the limiters of
+// the keys given the default limit are kept in a bounded cache.
+func (db *DB) limiter(key string) (*limiter, string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if l, ok := db.f.limits[key]; ok {
+		lim, ok := db.limiters[key]
+
+		if !ok {
+			if db.limiters == nil {
+				db.limiters = make(map[string]*limiter)
+			}
+
+			lim = newLimiter(l)
+			db.limiters[key] = lim
+		}
+
+		return lim, key
+	}
+
+	if db.f.defaultLimit == nil {
+		return nil, ""
+	}
+
+	lim := db.defaults.GetOrAdd(
+		key,
+		func(string) *limiter { return newLimiter(*db.f.defaultLimit) },
+	)
+
+	return lim, defaultKeyLabel
+}
+
+func (db *DB) acquire(ctx context.Context, op OpType, stmt string, vs []interface{}) (func(), error) {
+	l, label := db.limiter(db.f.key(ctx, op, stmt, vs))
+
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release, d, err := l.acquire(ctx)
+
+	if db.f.wait != nil {
+		outcome := "acquired"
+
+		if err != nil {
+			outcome = "canceled"
+		}
+
+		db.f.wait.WithLabelValues(label, string(op), outcome).Observe(d.Seconds())
+	}
+
+	return release, err
+}
+
+func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	release, err := db.acquire(ctx, Exec, stmt, vs)
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return db.db.Exec(ctx, stmt, vs...)
+}
+
+func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
+	return cql.LazyCASScanner(func() (cql.CASScanner, func(bool, error), error) {
+		release, err := db.acquire(ctx, ExecCAS, stmt, vs)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sc := db.db.ExecCAS(ctx, stmt, vs...)
+
+		return sc, func(bool, error) { release() }, nil
+	})
+}
+
+func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
+	return cql.LazyScanner(func() (cql.Scanner, func(error), error) {
+		release, err := db.acquire(ctx, QueryRow, stmt, vs)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return db.db.QueryRow(ctx, stmt, vs...), func(error) { release() }, nil
+	})
+}
+
+type cursor struct {
+	cql.Cursor
+
+	release func()
+}
+
+func (c cursor) MapScan(m map[string]interface{}) bool {
+	return cql.CursorMapScan(c.Cursor, m)
+}
+
+func (c cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
+func (c cursor) Close() error {
+	defer c.release()
+
+	return c.Cursor.Close()
+}
+
+// Query holds the in flight slot until the cursor is closed.
+func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
+	release, err := db.acquire(ctx, Query, stmt, vs)
+
+	if err != nil {
+		return cql.ErrCursor(err)
+	}
+
+	return cursor{Cursor: db.db.Query(ctx, stmt, vs...), release: release}
+}
+
+type batch struct {
+	cql.Batch
+
+	db   *DB
+	ctx  context.Context
+	opts []interface{}
+}
+
+func (b *batch) Exec() error {
+	release, err := b.db.acquire(b.ctx, Batch, "", b.opts)
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return b.Batch.Exec()
+}
+
+func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
+	release, err := b.db.acquire(b.ctx, Batch, "", b.opts)
+
+	if err != nil {
+		return false, nil, err
+	}
+
+	defer release()
+
+	return b.Batch.ExecCAS()
+}
+
+func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	vs := cql.OptionValues(opts)
+
+	return &batch{Batch: db.db.Batch(ctx, bt, opts...), db: db, ctx: ctx, opts: vs}
+}
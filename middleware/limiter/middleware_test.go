@@ -0,0 +1,179 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
+
+func TestMaxInflight(t *testing.T) {
+	var (
+		reg = prometheus.NewPedanticRegistry()
+		db  = NewFactory(
+			WithKeyFunc(ByNamedQuery),
+			WithLimit("scroll", Limit{MaxInflight: 1}),
+			WithRegisterer(reg),
+		).Wrap(&cqltest.MockDB{})
+
+		ctx = context.Background()
+		nq  = cql.NamedQuery("scroll")
+	)
+
+	cur := db.Query(ctx, "SELECT", nq)
+
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, db.Exec(tctx, "INSERT", nq), context.DeadlineExceeded)
+
+	// Other keys are not limited.
+	assert.NoError(t, db.Exec(tctx, "INSERT"))
+
+	require.NoError(t, cur.Close())
+	assert.NoError(t, db.Exec(ctx, "INSERT", nq))
+
+	n, err := testutil.GatherAndCount(reg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestScannerSlot(t *testing.T) {
+	var (
+		db = NewFactory(
+			WithDefaultLimit(Limit{MaxInflight: 1}),
+		).Wrap(&cqltest.MockDB{})
+
+		ctx = context.Background()
+	)
+
+	// Scanners left unscanned do not hold any slot.
+	db.QueryRow(ctx, "SELECT")
+	db.ExecCAS(ctx, "UPDATE")
+
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, db.QueryRow(tctx, "SELECT").Scan())
+
+	_, err := db.ExecCAS(tctx, "UPDATE").ScanCAS()
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(tctx, "INSERT"))
+
+	// The slot is held from Columns until the row is scanned.
+	cis := []cql.ColumnInfo{{Name: "id"}}
+	db = NewFactory(
+		WithDefaultLimit(Limit{MaxInflight: 1}),
+	).Wrap(&cqltest.MockDB{ColumnInfos: cis})
+
+	sc := db.QueryRow(ctx, "SELECT id FROM users")
+	assert.Equal(t, cis, cql.Columns(sc))
+
+	tctx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, db.Exec(tctx, "INSERT"), context.DeadlineExceeded)
+	assert.NoError(t, sc.Scan())
+	assert.NoError(t, db.Exec(ctx, "INSERT"))
+}
+
+func TestRate(t *testing.T) {
+	var (
+		db = NewFactory(
+			WithKeyFunc(ByOpType),
+			WithDefaultLimit(Limit{Rate: 1, Burst: 1}),
+		).Wrap(&cqltest.MockDB{})
+
+		ctx = context.Background()
+	)
+
+	assert.NoError(t, db.Exec(ctx, "INSERT"))
+	assert.NoError(t, db.Query(ctx, "SELECT").Close())
+
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, db.Exec(tctx, "INSERT"), context.DeadlineExceeded)
+}
+
+func TestCanceledSlotKeepsToken(t *testing.T) {
+	var (
+		db = NewFactory(
+			WithDefaultLimit(Limit{MaxInflight: 1, Rate: 1, Burst: 2}),
+		).Wrap(&cqltest.MockDB{})
+
+		ctx = context.Background()
+	)
+
+	cur := db.Query(ctx, "SELECT")
+
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, db.Exec(tctx, "INSERT"), context.DeadlineExceeded)
+	require.NoError(t, cur.Close())
+
+	tctx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, db.Exec(tctx, "INSERT"))
+}
+
+func TestMaxKeys(t *testing.T) {
+	var (
+		reg = prometheus.NewPedanticRegistry()
+		db  = NewFactory(
+			WithKeyFunc(ByTag("tenant")),
+			WithDefaultLimit(Limit{MaxInflight: 1}),
+			WithMaxKeys(1),
+			WithRegisterer(reg),
+		).Wrap(&cqltest.MockDB{}).(*DB)
+	)
+
+	for _, tenant := range []string{"x", "y", "z"} {
+		ctx := cql.ContextWithTags(context.Background(), "tenant", tenant)
+
+		assert.NoError(t, db.Exec(ctx, "INSERT"))
+	}
+
+	assert.Equal(t, 1, db.defaults.Len())
+
+	// Every key given the default limit shares the same label.
+	assert.Equal(t, 1, testutil.CollectAndCount(db.f.wait))
+}
+
+func TestByContextValue(t *testing.T) {
+	type key struct{}
+
+	fn := ByContextValue(key{})
+
+	assert.Equal(t, "", fn(context.Background(), Exec, "", nil))
+	assert.Equal(
+		t,
+		"batch",
+		fn(context.WithValue(context.Background(), key{}, "batch"), Exec, "", nil),
+	)
+}
+
+func TestByTag(t *testing.T) {
+	var (
+		fn  = ByTag("tenant")
+		ctx = cql.ContextWithTags(context.Background(), "tenant", "x")
+	)
+
+	assert.Equal(t, "", fn(context.Background(), Exec, "", nil))
+	assert.Equal(t, "x", fn(ctx, Exec, "", nil))
+	assert.Equal(
+		t,
+		"scroll",
+		ByNamedQuery(cql.ContextWithOptions(ctx, cql.NamedQuery("scroll")), Query, "", nil),
+	)
+}
@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"strings"
+
+	"github.com/upfluence/cql/internal/lru"
+)
+
+const columnCacheSize = 1024
+
+// Column is the column a bound value is compared to or written in, Name is
+// empty when the value is not tied to a column, like the LIMIT or the TTL.
+type Column struct {
+	Table string
+	Name  string
+}
+
+var (
+	// columnCache is keyed by the raw statements, it is bounded since the
+	// statements inlining their values are all distinct.
+	columnCache = lru.New[string, []Column](columnCacheSize)
+
+	resetKeywords = map[string]bool{
+		"AND": true, "WHERE": true, "SET": true, "IF": true, "LIMIT": true,
+		"USING": true, "TTL": true, "TIMESTAMP": true, "PER": true,
+	}
+
+	operators = map[string]bool{
+		"=": true, "<": true, ">": true, "<=": true, ">=": true, "!=": true,
+		"IN": true, "CONTAINS": true, "KEY": true,
+	}
+)
+
+type token struct {
+	v     string
+	ident bool
+}
+
+func tokenize(stmt string) []token {
+	var ts []token
+
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			j := i + 1
+
+			for j < len(stmt) {
+				if stmt[j] == '\'' {
+					if j+1 < len(stmt) && stmt[j+1] == '\'' {
+						j += 2
+						continue
+					}
+
+					break
+				}
+
+				j++
+			}
+
+			ts = append(ts, token{v: "'"})
+			i = j + 1
+		case c == '"':
+			j := strings.IndexByte(stmt[i+1:], '"')
+
+			if j < 0 {
+				j = len(stmt) - i - 1
+			}
+
+			ts = append(ts, token{v: stmt[i+1 : i+1+j], ident: true})
+			i += j + 2
+		case isIdentChar(c):
+			j := i
+
+			for j < len(stmt) && (isIdentChar(stmt[j]) || stmt[j] == '.') {
+				j++
+			}
+
+			ts = append(ts, token{v: stmt[i:j], ident: true})
+			i = j
+		case (c == '<' || c == '>' || c == '!') && i+1 < len(stmt) && stmt[i+1] == '=':
+			ts = append(ts, token{v: stmt[i : i+2]})
+			i += 2
+		default:
+			ts = append(ts, token{v: string(c)})
+			i++
+		}
+	}
+
+	return ts
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		('0' <= c && c <= '9')
+}
+
+func tableName(v string) string {
+	if i := strings.LastIndexByte(v, '.'); i >= 0 {
+		return v[i+1:]
+	}
+
+	return v
+}
+
+// parseColumns returns the column bound to each of the markers of the
+// statement. It only understands the shapes of statements produced by hand
+// or by x/cqlbuilder, the markers it can not tie to a column get an empty
+// name.
+func parseColumns(stmt string) []Column {
+	if cs, ok := columnCache.Get(stmt); ok {
+		return cs
+	}
+
+	var (
+		cs []Column
+
+		ts    = tokenize(stmt)
+		table string
+
+		into          bool
+		insertColumns []string
+		insertValues  bool
+		insertIndex   int
+
+		cur   string
+		depth int
+	)
+
+	for i, t := range ts {
+		kw := strings.ToUpper(t.v)
+
+		switch {
+		case t.ident && (kw == "FROM" || kw == "INTO" || kw == "UPDATE"):
+			if i+1 < len(ts) && ts[i+1].ident {
+				table = tableName(ts[i+1].v)
+			}
+
+			into = kw == "INTO"
+		case t.ident && kw == "VALUES":
+			insertValues = true
+			insertIndex = 0
+		case t.v == "(":
+			depth++
+
+			if into {
+				into = false
+
+				for j := i + 1; j < len(ts) && ts[j].v != ")"; j++ {
+					if ts[j].ident {
+						insertColumns = append(insertColumns, ts[j].v)
+					}
+				}
+			}
+		case t.v == ")":
+			depth--
+
+			if insertValues && depth == 0 {
+				insertValues = false
+			}
+		case t.v == ",":
+			if insertValues && depth == 1 {
+				insertIndex++
+			} else if depth == 0 {
+				cur = ""
+			}
+		case t.v == ";":
+			table, cur, depth = "", "", 0
+			into, insertColumns, insertValues = false, nil, false
+		case t.v == "?":
+			var name string
+
+			if insertValues {
+				if insertIndex < len(insertColumns) {
+					name = insertColumns[insertIndex]
+				}
+			} else {
+				name = cur
+			}
+
+			cs = append(cs, Column{Table: table, Name: name})
+		case t.ident && resetKeywords[kw]:
+			cur = ""
+		case operators[kw] && i > 0 && ts[i-1].ident && !operators[strings.ToUpper(ts[i-1].v)]:
+			cur = ts[i-1].v
+		}
+	}
+
+	columnCache.Add(stmt, cs)
+
+	return cs
+}
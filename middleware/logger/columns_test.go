@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColumns(t *testing.T) {
+	for _, tt := range []struct {
+		stmt string
+		want []Column
+	}{
+		{
+			stmt: "INSERT INTO ks.users(id, email, password) VALUES (?, ?, ?) USING TTL ?",
+			want: []Column{
+				{Table: "users", Name: "id"},
+				{Table: "users", Name: "email"},
+				{Table: "users", Name: "password"},
+				{Table: "users"},
+			},
+		},
+		{
+			stmt: "INSERT INTO users (id, \"Token\", created_at) VALUES (?, ?, toTimestamp(now()))",
+			want: []Column{
+				{Table: "users", Name: "id"},
+				{Table: "users", Name: "Token"},
+			},
+		},
+		{
+			stmt: "UPDATE users SET password = ?, visits = visits + ? WHERE id = ? IF email = ?",
+			want: []Column{
+				{Table: "users", Name: "password"},
+				{Table: "users", Name: "visits"},
+				{Table: "users", Name: "id"},
+				{Table: "users", Name: "email"},
+			},
+		},
+		{
+			stmt: "SELECT id, 'x' FROM users WHERE id IN (?, ?) AND tags CONTAINS ? AND ts >= ? LIMIT ?",
+			want: []Column{
+				{Table: "users", Name: "id"},
+				{Table: "users", Name: "id"},
+				{Table: "users", Name: "tags"},
+				{Table: "users", Name: "ts"},
+				{Table: "users"},
+			},
+		},
+		{
+			stmt: "DELETE FROM users WHERE id = ?",
+			want: []Column{{Table: "users", Name: "id"}},
+		},
+	} {
+		assert.Equal(t, tt.want, parseColumns(tt.stmt), tt.stmt)
+	}
+}
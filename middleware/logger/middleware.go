@@ -3,6 +3,8 @@ package logger
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -19,22 +21,77 @@ const (
 	ExecCAS  OpType = "ExecCAS"
 	QueryRow OpType = "QueryRow"
 	Query    OpType = "Query"
+	Batch    OpType = "Batch"
 )
 
 type Logger interface {
 	Log(OpType, string, []interface{}, error, time.Duration, ...record.Field)
 }
 
+// ContextLogger is a Logger also given the context of the operation.
+type ContextLogger interface {
+	LogContext(context.Context, OpType, string, []interface{}, error, time.Duration, ...record.Field)
+}
+
+type loggerAdapter struct {
+	Logger
+}
+
+func (la loggerAdapter) LogContext(_ context.Context, t OpType, q string, vs []interface{}, err error, d time.Duration, fs ...record.Field) {
+	la.Log(t, q, vs, err, d, fs...)
+}
+
+// AdaptLogger turns a Logger into a ContextLogger, it is returned as is if
+// it already implements ContextLogger.
+func AdaptLogger(l Logger) ContextLogger {
+	if cl, ok := l.(ContextLogger); ok {
+		return cl
+	}
+
+	return loggerAdapter{Logger: l}
+}
+
+// ContextFieldsFunc extracts the fields to log from the context of the
+// operation, like a request or a trace ID.
+type ContextFieldsFunc func(context.Context) []record.Field
+
 type simplifiedLogger struct {
 	level  record.Level
 	logger log.Logger
+
+	redactedColumns map[Column]struct{}
+	redactedTypes   map[reflect.Type]struct{}
+	maxValueSize    int
+	formatter       ValueFormatter
+	contextFields   []ContextFieldsFunc
+	batchDetails    bool
+}
+
+func newSimplifiedLogger(l log.Logger, lvl record.Level, opts []Option) simplifiedLogger {
+	sl := simplifiedLogger{logger: l, level: lvl}
+
+	for _, opt := range opts {
+		opt(&sl)
+	}
+
+	return sl
 }
 
 func (l *simplifiedLogger) Log(t OpType, q string, vs []interface{}, err error, d time.Duration, ofs ...record.Field) {
+	l.log(context.Background(), l.level, t, q, vs, err, d, ofs...)
+}
+
+func (l *simplifiedLogger) LogContext(ctx context.Context, t OpType, q string, vs []interface{}, err error, d time.Duration, ofs ...record.Field) {
+	l.log(ctx, l.level, t, q, vs, err, d, ofs...)
+}
+
+func (l *simplifiedLogger) log(ctx context.Context, lvl record.Level, t OpType, q string, vs []interface{}, err error, d time.Duration, ofs ...record.Field) {
 	var (
-		i               int
-		withConsistency bool
-		consistency     cql.Consistency
+		args                  []interface{}
+		withConsistency       bool
+		consistency           cql.Consistency
+		withSerialConsistency bool
+		serialConsistency     cql.SerialConsistency
 
 		fs = make([]record.Field, 0, 2+len(ofs))
 	)
@@ -47,42 +104,83 @@ func (l *simplifiedLogger) Log(t OpType, q string, vs []interface{}, err error,
 		case cql.WithConsistency:
 			withConsistency = true
 			consistency = cql.Consistency(vv)
+		case cql.WithSerialConsistency:
+			withSerialConsistency = true
+			serialConsistency = cql.SerialConsistency(vv)
 		case cql.Option:
 		default:
-			fs = append(fs, log.Field(fmt.Sprintf("$%d", i+1), v))
-			i++
+			args = append(args, v)
 		}
 	}
 
+	for i, v := range l.formatValues(q, args) {
+		fs = append(fs, log.Field(fmt.Sprintf("$%d", i+1), v))
+	}
+
 	if withConsistency {
 		fs = append(fs, log.Field("consistency", consistency))
 	}
 
+	if withSerialConsistency {
+		fs = append(fs, log.Field("serial_consistency", serialConsistency))
+	}
+
 	fs = append(fs, ofs...)
 
-	logger := l.logger
+	for _, fn := range l.contextFields {
+		fs = append(fs, fn(ctx)...)
+	}
+
+	logger := l.logger.WithContext(ctx)
 
 	if err != nil {
 		logger = logger.WithError(err)
 	}
 
-	logger.WithFields(fs...).Log(l.level, q)
+	logger.WithFields(fs...).Log(lvl, q)
+
+	if t != Batch || !l.batchDetails {
+		return
+	}
+
+	for _, f := range ofs {
+		if bqs, ok := f.(BatchQueries); ok {
+			for i, bq := range bqs {
+				l.log(
+					ctx,
+					lvl,
+					Query,
+					bq.Statement,
+					bq.Values,
+					err,
+					d,
+					log.Field("batch_index", i),
+				)
+			}
+		}
+	}
 }
 
 func NewFactory(l Logger) cql.MiddlewareFactory {
+	return NewContextFactory(AdaptLogger(l))
+}
+
+func NewContextFactory(l ContextLogger) cql.MiddlewareFactory {
 	return &factory{l: l}
 }
 
-func NewLevelFactory(l log.Logger, lvl record.Level) cql.MiddlewareFactory {
-	return NewFactory(&simplifiedLogger{logger: l, level: lvl})
+func NewLevelFactory(l log.Logger, lvl record.Level, opts ...Option) cql.MiddlewareFactory {
+	sl := newSimplifiedLogger(l, lvl, opts)
+
+	return NewFactory(&sl)
 }
 
-func NewDebugFactory(l log.Logger) cql.MiddlewareFactory {
-	return NewLevelFactory(l, record.Debug)
+func NewDebugFactory(l log.Logger, opts ...Option) cql.MiddlewareFactory {
+	return NewLevelFactory(l, record.Debug, opts...)
 }
 
 type factory struct {
-	l Logger
+	l ContextLogger
 }
 
 func (f *factory) Wrap(db cql.DB) cql.DB {
@@ -91,24 +189,36 @@ func (f *factory) Wrap(db cql.DB) cql.DB {
 
 type DB struct {
 	db cql.DB
-	l  Logger
+	l  ContextLogger
 }
 
-func (db *DB) Unwrap() cql.DB {
-	if u, ok := db.db.(interface{ Unwrap() cql.DB }); ok {
-		return u.Unwrap()
-	}
+func (db *DB) Unwrap() cql.DB { return db.db }
 
-	return db.db
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
 }
 
-func trimValues(vs []interface{}) ([]interface{}, []record.Field) {
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+// trimValues merges the options attached to the context with the values and
+// turns the named query and the context tags into fields.
+func trimValues(ctx context.Context, vs []interface{}) ([]interface{}, []record.Field) {
 	var fs []record.Field
 
-	for _, v := range vs {
-		if nq, ok := v.(cql.NamedQuery); ok {
-			fs = append(fs, log.Field("query", string(nq)))
-		}
+	vs = cql.WithContextOptions(ctx, vs)
+
+	if nq, ok := cql.LookupNamedQuery(vs); ok {
+		fs = append(fs, log.Field("query", nq))
+	}
+
+	for _, t := range cql.ContextTags(ctx) {
+		fs = append(fs, log.Field("tag_"+t.Key, t.Value))
 	}
 
 	return vs, fs
@@ -118,8 +228,8 @@ func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
 	t0 := time.Now()
 	err := db.db.Exec(ctx, stmt, vs...)
 
-	vs, fs := trimValues(vs)
-	db.l.Log(Exec, stmt, vs, err, time.Since(t0), fs...)
+	vs, fs := trimValues(ctx, vs)
+	db.l.LogContext(ctx, Exec, stmt, vs, err, time.Since(t0), fs...)
 
 	return err
 }
@@ -127,7 +237,8 @@ func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
 type casScanner struct {
 	cql.CASScanner
 
-	l    Logger
+	l    ContextLogger
+	ctx  context.Context
 	stmt string
 	vs   []interface{}
 	t0   time.Time
@@ -136,9 +247,10 @@ type casScanner struct {
 func (csc casScanner) ScanCAS(vs ...interface{}) (bool, error) {
 	ok, err := csc.CASScanner.ScanCAS(vs...)
 
-	vvs, fs := trimValues(csc.vs)
+	vvs, fs := trimValues(csc.ctx, csc.vs)
 
-	csc.l.Log(
+	csc.l.LogContext(
+		csc.ctx,
 		ExecCAS,
 		csc.stmt,
 		vvs,
@@ -155,24 +267,36 @@ func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.C
 
 	sc := db.db.ExecCAS(ctx, stmt, vs...)
 
-	return casScanner{CASScanner: sc, l: db.l, stmt: stmt, vs: vs, t0: t0}
+	return casScanner{CASScanner: sc, l: db.l, ctx: ctx, stmt: stmt, vs: vs, t0: t0}
 }
 
 type scanner struct {
 	cql.Scanner
 
-	l    Logger
+	l    ContextLogger
+	ctx  context.Context
 	stmt string
 	vs   []interface{}
 	t0   time.Time
 }
 
+func (sc scanner) Columns() []cql.ColumnInfo {
+	return cql.Columns(sc.Scanner)
+}
+
 func (sc scanner) Scan(vs ...interface{}) error {
-	err := sc.Scanner.Scan(vs...)
+	return sc.log(sc.Scanner.Scan(vs...))
+}
+
+func (sc scanner) MapScan(m map[string]interface{}) error {
+	return sc.log(cql.MapScan(sc.Scanner, m))
+}
 
-	vvs, fs := trimValues(sc.vs)
+func (sc scanner) log(err error) error {
+	vvs, fs := trimValues(sc.ctx, sc.vs)
 
-	sc.l.Log(
+	sc.l.LogContext(
+		sc.ctx,
 		QueryRow,
 		sc.stmt,
 		vvs,
@@ -189,38 +313,59 @@ func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.
 
 	sc := db.db.QueryRow(ctx, stmt, vs...)
 
-	return scanner{Scanner: sc, l: db.l, stmt: stmt, vs: vs, t0: t0}
+	return scanner{Scanner: sc, l: db.l, ctx: ctx, stmt: stmt, vs: vs, t0: t0}
 }
 
 type cursor struct {
 	cql.Cursor
 
-	l    Logger
+	l    ContextLogger
+	ctx  context.Context
 	stmt string
 	vs   []interface{}
 	t0   time.Time
+	t1   time.Time
 
 	scanned uint32
 }
 
+func (c *cursor) scan() {
+	if atomic.AddUint32(&c.scanned, 1) == 1 {
+		c.t1 = time.Now()
+	}
+}
+
 func (c *cursor) Scan(vs ...interface{}) bool {
-	atomic.AddUint32(&c.scanned, 1)
+	c.scan()
 
 	return c.Cursor.Scan(vs...)
 }
 
+func (c *cursor) MapScan(m map[string]interface{}) bool {
+	c.scan()
+
+	return cql.CursorMapScan(c.Cursor, m)
+}
+
+func (c *cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c *cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
 func (c *cursor) Close() error {
 	err := c.Cursor.Close()
-	vvs, fs := trimValues(c.vs)
+	vvs, fs := trimValues(c.ctx, c.vs)
 
-	c.l.Log(
-		Query,
-		c.stmt,
-		vvs,
-		err,
-		time.Since(c.t0),
-		append(fs, log.Field("scanned", int64(c.scanned)))...,
-	)
+	fs = append(fs, log.Field("scanned", int64(c.scanned)))
+
+	if !c.t1.IsZero() {
+		fs = append(fs, scanDurationField(time.Since(c.t1)))
+	}
+
+	c.l.LogContext(c.ctx, Query, c.stmt, vvs, err, time.Since(c.t0), fs...)
 
 	return err
 }
@@ -230,39 +375,74 @@ func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cur
 
 	c := db.db.Query(ctx, stmt, vs...)
 
-	return &cursor{Cursor: c, l: db.l, stmt: stmt, vs: vs, t0: t0}
+	return &cursor{Cursor: c, l: db.l, ctx: ctx, stmt: stmt, vs: vs, t0: t0}
 }
 
+type BatchQuery struct {
+	Statement string
+	Values    []interface{}
+}
+
+// BatchQueries is the field given to the logger along the Batch operations,
+// it carries the statements of the batch and logs as their count.
+type BatchQueries []BatchQuery
+
+func (BatchQueries) GetKey() string       { return "queries" }
+func (bqs BatchQueries) GetValue() string { return strconv.Itoa(len(bqs)) }
+
 type batch struct {
 	cql.Batch
 
-	l Logger
+	l   ContextLogger
+	ctx context.Context
 
 	bt      cql.BatchType
-	queries uint32
+	opts    []interface{}
+	queries BatchQueries
 }
 
 func (b *batch) Query(stmt string, vs ...interface{}) {
-	atomic.AddUint32(&b.queries, 1)
-
-	b.l.Log(Query, stmt, vs, nil, 0)
+	b.queries = append(b.queries, BatchQuery{Statement: stmt, Values: vs})
 
 	b.Batch.Query(stmt, vs...)
 }
 
+// log writes the batch as a single statement, the values of every child
+// statement are appended to the batch options.
+func (b *batch) log(err error, d time.Duration, fs ...record.Field) {
+	var (
+		stmts = make([]string, len(b.queries))
+
+		vs, tfs = trimValues(b.ctx, b.opts)
+	)
+
+	vs = append([]interface{}{}, vs...)
+
+	for i, q := range b.queries {
+		stmts[i] = q.Statement
+		vs = append(vs, q.Values...)
+	}
+
+	b.l.LogContext(
+		b.ctx,
+		Batch,
+		cql.BatchStatement(b.bt, stmts),
+		vs,
+		err,
+		d,
+		append(
+			append(fs, tfs...),
+			b.queries,
+			log.Field("batch_type", b.bt),
+		)...,
+	)
+}
+
 func (b *batch) Exec() error {
 	t0 := time.Now()
 	err := b.Batch.Exec()
 
-	b.l.Log(
-		Exec,
-		"",
-		nil,
-		err,
-		time.Since(t0),
-		log.Field("queries", int64(b.queries)),
-		log.Field("batch_type", b.bt),
-	)
+	b.log(err, time.Since(t0))
 
 	return err
 }
@@ -271,20 +451,19 @@ func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
 	t0 := time.Now()
 	ok, cur, err := b.Batch.ExecCAS()
 
-	b.l.Log(
-		Exec,
-		"",
-		nil,
-		err,
-		time.Since(t0),
-		log.Field("applied", ok),
-		log.Field("queries", int64(b.queries)),
-		log.Field("batch_type", b.bt),
-	)
+	b.log(err, time.Since(t0), log.Field("applied", ok))
 
 	return ok, cur, err
 }
 
 func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
-	return &batch{Batch: db.db.Batch(ctx, bt, opts...), l: db.l, bt: bt}
+	vs := cql.OptionValues(opts)
+
+	return &batch{
+		Batch: db.db.Batch(ctx, bt, opts...),
+		l:     db.l,
+		ctx:   ctx,
+		bt:    bt,
+		opts:  vs,
+	}
 }
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/upfluence/log"
+	"github.com/upfluence/log/record"
+
+	"github.com/upfluence/cql"
+)
+
+type mockDB struct {
+	cql.DB
+}
+
+func (mockDB) Exec(context.Context, string, ...interface{}) error { return nil }
+
+func (mockDB) Batch(context.Context, cql.BatchType, ...cql.Option) cql.Batch {
+	return mockBatch{}
+}
+
+type mockBatch struct{}
+
+func (mockBatch) Query(string, ...interface{})       {}
+func (mockBatch) Exec() error                        { return nil }
+func (mockBatch) ExecCAS() (bool, cql.Cursor, error) { return true, nil, nil }
+
+type requestIDKey struct{}
+
+type plainLogger struct {
+	stmts []string
+}
+
+func (pl *plainLogger) Log(_ OpType, q string, _ []interface{}, _ error, _ time.Duration, _ ...record.Field) {
+	pl.stmts = append(pl.stmts, q)
+}
+
+func TestContextFields(t *testing.T) {
+	var (
+		s  recordSink
+		db = NewLevelFactory(
+			log.NewLogger(
+				log.WithSink(&s),
+				log.WithDefaultFieldThreshold(record.Debug),
+			),
+			record.Info,
+			WithContextFields(
+				func(ctx context.Context) []record.Field {
+					if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+						return []record.Field{log.Field("request_id", id)}
+					}
+
+					return nil
+				},
+			),
+		).Wrap(mockDB{})
+
+		ctx = context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	)
+
+	ctx = cql.ContextWithTags(ctx, "tenant", "x")
+	ctx = cql.ContextWithOptions(ctx, cql.WithConsistency(cql.LocalOne))
+
+	assert.NoError(t, db.Exec(ctx, "INSERT INTO users(id) VALUES (?)", 1))
+	assert.NoError(t, db.Exec(context.Background(), "DELETE FROM users"))
+
+	assert.Len(t, s.fields, 2)
+	assert.Equal(t, "req-1", s.fields[0]["request_id"])
+	assert.Equal(t, "1", s.fields[0]["$1"])
+	assert.Equal(t, "x", s.fields[0]["tag_tenant"])
+	assert.Equal(t, "LocalOne", s.fields[0]["consistency"])
+	assert.NotContains(t, s.fields[1], "request_id")
+}
+
+func TestAdaptLogger(t *testing.T) {
+	var pl plainLogger
+
+	db := NewFactory(&pl).Wrap(mockDB{})
+
+	assert.NoError(t, db.Exec(context.Background(), "DELETE FROM users"))
+	assert.Equal(t, []string{"DELETE FROM users"}, pl.stmts)
+
+	sl := NewSlowLogger(log.NewLogger(), record.Info)
+	assert.Equal(t, sl, AdaptLogger(sl))
+}
+
+func TestBatch(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+
+		wantStmts  []string
+		wantFields []map[string]string
+	}{
+		{
+			name: "single record",
+			opts: []Option{WithRedactedColumns("users", "password")},
+			wantStmts: []string{
+				"BEGIN UNLOGGED BATCH INSERT INTO users(id, password) VALUES (?, ?); " +
+					"DELETE FROM sessions WHERE id = ?; APPLY BATCH",
+			},
+			wantFields: []map[string]string{
+				{
+					"op_type":     "Batch",
+					"batch_type":  "UnloggedBatch",
+					"consistency": "LocalQuorum",
+					"queries":     "2",
+					"$1":          "1",
+					"$2":          redactedValue,
+					"$3":          "2",
+				},
+			},
+		},
+		{
+			name: "details",
+			opts: []Option{WithBatchDetails},
+			wantStmts: []string{
+				"BEGIN UNLOGGED BATCH INSERT INTO users(id, password) VALUES (?, ?); " +
+					"DELETE FROM sessions WHERE id = ?; APPLY BATCH",
+				"INSERT INTO users(id, password) VALUES (?, ?)",
+				"DELETE FROM sessions WHERE id = ?",
+			},
+			wantFields: []map[string]string{
+				{
+					"op_type":     "Batch",
+					"batch_type":  "UnloggedBatch",
+					"consistency": "LocalQuorum",
+					"queries":     "2",
+					"$1":          "1",
+					"$2":          "hunter2",
+					"$3":          "2",
+				},
+				{"op_type": "Query", "batch_index": "0", "$1": "1", "$2": "hunter2"},
+				{"op_type": "Query", "batch_index": "1", "$1": "2"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var s recordSink
+
+			db := NewLevelFactory(
+				log.NewLogger(
+					log.WithSink(&s),
+					log.WithDefaultFieldThreshold(record.Debug),
+				),
+				record.Info,
+				tt.opts...,
+			).Wrap(mockDB{})
+
+			b := db.Batch(
+				context.Background(),
+				cql.UnloggedBatch,
+				cql.WithConsistency(cql.LocalQuorum),
+			)
+
+			b.Query("INSERT INTO users(id, password) VALUES (?, ?)", 1, "hunter2")
+			b.Query("DELETE FROM sessions WHERE id = ?", 2)
+
+			assert.NoError(t, b.Exec())
+			assert.Equal(t, tt.wantStmts, s.stmts)
+			assert.Len(t, s.fields, len(tt.wantFields))
+
+			for i, fs := range tt.wantFields {
+				for k, v := range fs {
+					assert.Equal(t, v, s.fields[i][k], k)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package logger
+
+type Option func(*simplifiedLogger)
+
+// WithContextFields adds the fields extracted from the context of the
+// operation to the logs.
+func WithContextFields(fns ...ContextFieldsFunc) Option {
+	return func(l *simplifiedLogger) {
+		l.contextFields = append(l.contextFields, fns...)
+	}
+}
+
+// WithBatchDetails also logs each statement of a batch as its own Query
+// record carrying its index in the batch.
+var WithBatchDetails Option = func(l *simplifiedLogger) { l.batchDetails = true }
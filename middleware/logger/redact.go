@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+const redactedValue = "[REDACTED]"
+
+// ValueFormatter turns a bound value into the value logged, col is the
+// column the value is bound to.
+type ValueFormatter func(col Column, v interface{}) interface{}
+
+// TruncateValues returns a ValueFormatter cutting the strings and byte
+// slices longer than max bytes, the strings are cut on a rune boundary and
+// the byte slices are written in hexadecimal. A max lower or equal to 0
+// disables the truncation.
+func TruncateValues(max int) ValueFormatter {
+	return func(_ Column, v interface{}) interface{} {
+		if max <= 0 {
+			return v
+		}
+
+		switch vv := v.(type) {
+		case string:
+			if len(vv) > max {
+				n := max
+
+				for n > 0 && !utf8.RuneStart(vv[n]) {
+					n--
+				}
+
+				return fmt.Sprintf("%s... (%d bytes)", vv[:n], len(vv))
+			}
+		case []byte:
+			if len(vv) > max {
+				return fmt.Sprintf("0x%x... (%d bytes)", vv[:max], len(vv))
+			}
+
+			return fmt.Sprintf("0x%x", vv)
+		}
+
+		return v
+	}
+}
+
+// WithRedactedColumns redacts the values bound to the given columns of the
+// table, an empty table matches every table.
+func WithRedactedColumns(table string, cols ...string) Option {
+	return func(l *simplifiedLogger) {
+		if l.redactedColumns == nil {
+			l.redactedColumns = make(map[Column]struct{})
+		}
+
+		for _, c := range cols {
+			l.redactedColumns[Column{Table: table, Name: strings.ToLower(c)}] = struct{}{}
+		}
+	}
+}
+
+// WithRedactedTypes redacts the values having the same type as one of the
+// given values.
+func WithRedactedTypes(vs ...interface{}) Option {
+	return func(l *simplifiedLogger) {
+		if l.redactedTypes == nil {
+			l.redactedTypes = make(map[reflect.Type]struct{})
+		}
+
+		for _, v := range vs {
+			l.redactedTypes[reflect.TypeOf(v)] = struct{}{}
+		}
+	}
+}
+
+// WithMaxValueSize truncates the strings and byte slices longer than n bytes,
+// see TruncateValues. The values are not truncated by default and it is
+// ignored when a custom formatter is given.
+func WithMaxValueSize(n int) Option {
+	return func(l *simplifiedLogger) { l.maxValueSize = n }
+}
+
+func WithValueFormatter(fn ValueFormatter) Option {
+	return func(l *simplifiedLogger) { l.formatter = fn }
+}
+
+func (l *simplifiedLogger) isRedacted(col Column, v interface{}) bool {
+	if _, ok := l.redactedTypes[reflect.TypeOf(v)]; ok {
+		return true
+	}
+
+	if col.Name == "" || len(l.redactedColumns) == 0 {
+		return false
+	}
+
+	name := strings.ToLower(col.Name)
+
+	for _, c := range []Column{{Table: col.Table, Name: name}, {Name: name}} {
+		if _, ok := l.redactedColumns[c]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *simplifiedLogger) formatValues(stmt string, vs []interface{}) []interface{} {
+	var (
+		cols []Column
+		fvs  = make([]interface{}, len(vs))
+	)
+
+	if len(l.redactedColumns) > 0 || l.formatter != nil {
+		cols = parseColumns(stmt)
+	}
+
+	for i, v := range vs {
+		var col Column
+
+		if i < len(cols) {
+			col = cols[i]
+		}
+
+		switch {
+		case l.isRedacted(col, v):
+			fvs[i] = redactedValue
+		case l.formatter != nil:
+			fvs[i] = l.formatter(col, v)
+		case l.maxValueSize > 0:
+			fvs[i] = TruncateValues(l.maxValueSize)(col, v)
+		default:
+			fvs[i] = v
+		}
+	}
+
+	return fvs
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/upfluence/log/record"
+)
+
+type secret string
+
+func TestFormatValues(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		stmt string
+		vs   []interface{}
+		want []interface{}
+	}{
+		{
+			name: "no truncation by default",
+			stmt: "INSERT INTO files(id, body, raw) VALUES (?, ?, ?)",
+			vs:   []interface{}{1, strings.Repeat("a", 300), []byte{0xca, 0xfe}},
+			want: []interface{}{1, strings.Repeat("a", 300), []byte{0xca, 0xfe}},
+		},
+		{
+			name: "truncation",
+			opts: []Option{WithMaxValueSize(256)},
+			stmt: "INSERT INTO files(id, body, raw) VALUES (?, ?, ?)",
+			vs:   []interface{}{1, strings.Repeat("a", 300), []byte{0xca, 0xfe}},
+			want: []interface{}{
+				1,
+				strings.Repeat("a", 256) + "... (300 bytes)",
+				"0xcafe",
+			},
+		},
+		{
+			name: "truncation on rune boundary",
+			opts: []Option{WithMaxValueSize(2)},
+			stmt: "INSERT INTO users(id, name) VALUES (?, ?)",
+			vs:   []interface{}{1, "héllo"},
+			want: []interface{}{1, "h... (6 bytes)"},
+		},
+		{
+			name: "redacted columns",
+			opts: []Option{
+				WithRedactedColumns("users", "Password"),
+				WithRedactedColumns("", "token"),
+				WithMaxValueSize(2),
+			},
+			stmt: "UPDATE users SET password = ?, token = ?, name = ? WHERE id = ?",
+			vs:   []interface{}{"hunter2", "abc", "john", 1},
+			want: []interface{}{redactedValue, redactedValue, "jo... (4 bytes)", 1},
+		},
+		{
+			name: "other table",
+			opts: []Option{WithRedactedColumns("users", "password")},
+			stmt: "SELECT * FROM accounts WHERE password = ?",
+			vs:   []interface{}{"hunter2"},
+			want: []interface{}{"hunter2"},
+		},
+		{
+			name: "redacted types",
+			opts: []Option{WithRedactedTypes(secret(""))},
+			stmt: "SELECT * FROM accounts WHERE id = ? AND key = ?",
+			vs:   []interface{}{"foo", secret("bar")},
+			want: []interface{}{"foo", redactedValue},
+		},
+		{
+			name: "custom formatter",
+			opts: []Option{
+				WithValueFormatter(func(c Column, v interface{}) interface{} {
+					return c.Table + "." + c.Name
+				}),
+			},
+			stmt: "SELECT * FROM accounts WHERE id = ? LIMIT ?",
+			vs:   []interface{}{"foo", 10},
+			want: []interface{}{"accounts.id", "accounts."},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newSimplifiedLogger(nil, record.Debug, tt.opts)
+
+			assert.Equal(t, tt.want, l.formatValues(tt.stmt, tt.vs))
+		})
+	}
+}
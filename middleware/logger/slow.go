@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/upfluence/log"
+	"github.com/upfluence/log/record"
+
+	"github.com/upfluence/cql"
+)
+
+const defaultSlowThreshold = 100 * time.Millisecond
+
+// scanDurationField carries the time elapsed between the first Scan of a
+// cursor and its Close.
+type scanDurationField time.Duration
+
+func (scanDurationField) GetKey() string     { return "scan_duration" }
+func (f scanDurationField) GetValue() string { return time.Duration(f).String() }
+
+type levelThreshold struct {
+	d     time.Duration
+	level record.Level
+}
+
+type SlowOption func(*slowLogger)
+
+// WithThreshold sets the duration above which an operation is logged, it
+// applies to the total duration of the operation. It defaults to 100ms.
+func WithThreshold(d time.Duration) SlowOption {
+	return func(l *slowLogger) { l.threshold = d }
+}
+
+// WithScanThreshold sets the duration between the first Scan and the Close
+// of a cursor above which the cursor is logged. It defaults to the
+// threshold.
+func WithScanThreshold(d time.Duration) SlowOption {
+	return func(l *slowLogger) { l.scanThreshold = &d }
+}
+
+// WithSampleRate sets the ratio, between 0 and 1, of the operations neither
+// slow nor failed which are logged anyway.
+func WithSampleRate(r float64) SlowOption {
+	return func(l *slowLogger) { l.sampleRate = r }
+}
+
+// WithLevelThreshold logs the operations lasting at least d at the level
+// lvl. When several thresholds match the highest level wins.
+func WithLevelThreshold(d time.Duration, lvl record.Level) SlowOption {
+	return func(l *slowLogger) {
+		l.levels = append(l.levels, levelThreshold{d: d, level: lvl})
+	}
+}
+
+// WithLoggerOptions applies the redaction and formatting options to the
+// logged operations.
+func WithLoggerOptions(opts ...Option) SlowOption {
+	return func(l *slowLogger) { l.opts = append(l.opts, opts...) }
+}
+
+type slowLogger struct {
+	simplifiedLogger
+
+	threshold     time.Duration
+	scanThreshold *time.Duration
+	sampleRate    float64
+	levels        []levelThreshold
+	opts          []Option
+
+	rand func() float64
+}
+
+// NewSlowLogger returns a Logger emitting the failed operations, the ones
+// slower than the threshold and a sample of the others.
+func NewSlowLogger(l log.Logger, lvl record.Level, opts ...SlowOption) Logger {
+	sl := slowLogger{threshold: defaultSlowThreshold, rand: rand.Float64}
+
+	for _, opt := range opts {
+		opt(&sl)
+	}
+
+	sl.simplifiedLogger = newSimplifiedLogger(l, lvl, sl.opts)
+
+	return &sl
+}
+
+func NewSlowFactory(l log.Logger, lvl record.Level, opts ...SlowOption) cql.MiddlewareFactory {
+	return NewFactory(NewSlowLogger(l, lvl, opts...))
+}
+
+func (l *slowLogger) isSlow(d time.Duration, fs []record.Field) bool {
+	if d >= l.threshold {
+		return true
+	}
+
+	st := l.threshold
+
+	if l.scanThreshold != nil {
+		st = *l.scanThreshold
+	}
+
+	for _, f := range fs {
+		if sd, ok := f.(scanDurationField); ok && time.Duration(sd) >= st {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *slowLogger) Log(t OpType, q string, vs []interface{}, err error, d time.Duration, fs ...record.Field) {
+	l.LogContext(context.Background(), t, q, vs, err, d, fs...)
+}
+
+func (l *slowLogger) LogContext(ctx context.Context, t OpType, q string, vs []interface{}, err error, d time.Duration, fs ...record.Field) {
+	if err == nil && !l.isSlow(d, fs) && (l.sampleRate <= 0 || l.rand() >= l.sampleRate) {
+		return
+	}
+
+	lvl := l.level
+
+	for _, lt := range l.levels {
+		if d >= lt.d && lt.level > lvl {
+			lvl = lt.level
+		}
+	}
+
+	l.log(ctx, lvl, t, q, vs, err, d, fs...)
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/upfluence/log"
+	"github.com/upfluence/log/record"
+)
+
+type recordSink struct {
+	stmts  []string
+	levels []record.Level
+	fields []map[string]string
+}
+
+func (s *recordSink) Log(r record.Record) error {
+	var b strings.Builder
+
+	r.WriteFormatted(&b)
+
+	s.stmts = append(s.stmts, b.String())
+	s.levels = append(s.levels, r.Level())
+
+	fs := make(map[string]string)
+
+	for _, f := range r.Fields() {
+		fs[f.GetKey()] = f.GetValue()
+	}
+
+	s.fields = append(s.fields, fs)
+
+	return nil
+}
+
+func TestSlowLogger(t *testing.T) {
+	var (
+		s  recordSink
+		sl = NewSlowLogger(
+			log.NewLogger(
+				log.WithSink(&s),
+				log.WithDefaultFieldThreshold(record.Debug),
+				log.WithDefaultErrorThreshold(record.Debug),
+			),
+			record.Info,
+			WithThreshold(100*time.Millisecond),
+			WithScanThreshold(50*time.Millisecond),
+			WithSampleRate(0.5),
+			WithLevelThreshold(time.Second, record.Warning),
+			WithLevelThreshold(10*time.Second, record.Error),
+		).(*slowLogger)
+	)
+
+	sl.rand = func() float64 { return 0.7 }
+
+	sl.Log(Exec, "fast", nil, nil, time.Millisecond)
+	sl.Log(Exec, "failed", nil, errors.New("boom"), time.Millisecond)
+	sl.Log(Exec, "slow", nil, nil, 200*time.Millisecond)
+	sl.Log(Exec, "very slow", nil, nil, 2*time.Second)
+	sl.Log(Exec, "very very slow", nil, nil, time.Minute)
+	sl.Log(Query, "slow scan", nil, nil, 60*time.Millisecond, scanDurationField(55*time.Millisecond))
+	sl.Log(Query, "fast scan", nil, nil, 60*time.Millisecond, scanDurationField(5*time.Millisecond))
+
+	sl.rand = func() float64 { return 0.2 }
+
+	sl.Log(Exec, "sampled", nil, nil, time.Millisecond)
+
+	assert.Equal(
+		t,
+		[]string{"failed", "slow", "very slow", "very very slow", "slow scan", "sampled"},
+		s.stmts,
+	)
+
+	assert.Equal(
+		t,
+		[]record.Level{
+			record.Info,
+			record.Info,
+			record.Warning,
+			record.Error,
+			record.Info,
+			record.Info,
+		},
+		s.levels,
+	)
+}
+
+func TestSlowLoggerDefaultThreshold(t *testing.T) {
+	var s recordSink
+
+	sl := NewSlowLogger(
+		log.NewLogger(log.WithSink(&s)),
+		record.Info,
+		WithSampleRate(0.5),
+	).(*slowLogger)
+
+	sl.rand = func() float64 { return 0.7 }
+
+	sl.Log(Exec, "fast", nil, nil, time.Millisecond)
+	sl.Log(Exec, "slow", nil, nil, time.Second)
+
+	assert.Equal(t, []string{"slow"}, s.stmts)
+}
@@ -0,0 +1,375 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/upfluence/cql"
+)
+
+type OpType string
+
+const (
+	Exec         OpType = "Exec"
+	ExecCAS      OpType = "ExecCAS"
+	QueryRow     OpType = "QueryRow"
+	Query        OpType = "Query"
+	BatchExec    OpType = "BatchExec"
+	BatchExecCAS OpType = "BatchExecCAS"
+
+	defaultNamespace = "cql"
+
+	unnamedQuery       = "unnamed"
+	defaultConsistency = "default"
+	batchQuery         = "batch"
+)
+
+var errorClasses = map[error]string{
+	cql.ErrNoRows:        "no_rows",
+	cql.ErrClosed:        "closed",
+	cql.ErrReadTimeout:   "read_timeout",
+	cql.ErrWriteTimeout:  "write_timeout",
+	cql.ErrUnavailable:   "unavailable",
+	cql.ErrOverloaded:    "overloaded",
+	cql.ErrAlreadyExists: "already_exists",
+	cql.ErrInvalidQuery:  "invalid_query",
+	cql.ErrUnauthorized:  "unauthorized",
+}
+
+func errorClass(err error) string {
+	if err == nil {
+		return "none"
+	}
+
+	if c := cql.ErrorClass(err); c != nil {
+		err = c
+	}
+
+	for e, c := range errorClasses {
+		if errors.Is(err, e) {
+			return c
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "deadline_exceeded"
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+
+	return "unknown"
+}
+
+type Option func(*options)
+
+type options struct {
+	namespace   string
+	buckets     []float64
+	rowsBuckets []float64
+	tags        []string
+}
+
+func WithNamespace(ns string) Option {
+	return func(o *options) { o.namespace = ns }
+}
+
+func WithBuckets(bs []float64) Option {
+	return func(o *options) { o.buckets = bs }
+}
+
+func WithRowsBuckets(bs []float64) Option {
+	return func(o *options) { o.rowsBuckets = bs }
+}
+
+// WithTagLabels adds a label to the duration histogram for each of the
+// given context tags, see cql.ContextWithTags. The operations not tagged get
+// an empty value.
+func WithTagLabels(keys ...string) Option {
+	return func(o *options) { o.tags = append(o.tags, keys...) }
+}
+
+type factory struct {
+	tags []string
+
+	duration *prometheus.HistogramVec
+	rows     *prometheus.HistogramVec
+	cas      *prometheus.CounterVec
+	inflight *prometheus.GaugeVec
+}
+
+// NewFactory returns a middleware factory recording its metrics on reg, the
+// collectors already registered by another factory sharing reg are reused.
+func NewFactory(reg prometheus.Registerer, opts ...Option) cql.MiddlewareFactory {
+	o := options{
+		namespace:   defaultNamespace,
+		buckets:     prometheus.DefBuckets,
+		rowsBuckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &factory{
+		tags: o.tags,
+		duration: register(
+			reg,
+			prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Namespace: o.namespace,
+					Name:      "operation_duration_seconds",
+					Help:      "Duration of the operations",
+					Buckets:   o.buckets,
+				},
+				append([]string{"op", "query", "consistency", "error"}, o.tags...),
+			),
+		),
+		rows: register(
+			reg,
+			prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Namespace: o.namespace,
+					Name:      "cursor_scanned_rows",
+					Help:      "Number of rows scanned per cursor",
+					Buckets:   o.rowsBuckets,
+				},
+				[]string{"query"},
+			),
+		),
+		cas: register(
+			reg,
+			prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: o.namespace,
+					Name:      "cas_total",
+					Help:      "Number of lightweight transactions by outcome",
+				},
+				[]string{"op", "query", "applied"},
+			),
+		),
+		inflight: register(
+			reg,
+			prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: o.namespace,
+					Name:      "inflight_operations",
+					Help:      "Number of operations in flight",
+				},
+				[]string{"op"},
+			),
+		),
+	}
+}
+
+func (f *factory) Wrap(db cql.DB) cql.DB {
+	return &DB{db: db, f: f}
+}
+
+type DB struct {
+	db cql.DB
+	f  *factory
+}
+
+func (db *DB) Unwrap() cql.DB { return db.db }
+
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
+}
+
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+type labels struct {
+	query       string
+	consistency string
+	tags        []string
+}
+
+func (f *factory) parseLabels(ctx context.Context, vs []interface{}) labels {
+	l := labels{
+		query:       unnamedQuery,
+		consistency: defaultConsistency,
+		tags:        make([]string, len(f.tags)),
+	}
+
+	for _, v := range cql.WithContextOptions(ctx, vs) {
+		switch vv := v.(type) {
+		case cql.NamedQuery:
+			l.query = string(vv)
+		case cql.WithConsistency:
+			l.consistency = cql.Consistency(vv).String()
+		}
+	}
+
+	for i, k := range f.tags {
+		l.tags[i], _ = cql.ContextTag(ctx, k)
+	}
+
+	return l
+}
+
+type observation struct {
+	f  *factory
+	op OpType
+	l  labels
+	t0 time.Time
+}
+
+func (f *factory) begin(op OpType, l labels) observation {
+	f.inflight.WithLabelValues(string(op)).Inc()
+
+	return observation{f: f, op: op, l: l, t0: time.Now()}
+}
+
+func (o observation) end(err error) {
+	o.f.inflight.WithLabelValues(string(o.op)).Dec()
+	o.f.duration.WithLabelValues(
+		append(
+			[]string{string(o.op), o.l.query, o.l.consistency, errorClass(err)},
+			o.l.tags...,
+		)...,
+	).Observe(time.Since(o.t0).Seconds())
+}
+
+func (o observation) endCAS(ok bool, err error) {
+	o.end(err)
+
+	if err == nil {
+		o.f.cas.WithLabelValues(
+			string(o.op),
+			o.l.query,
+			strconv.FormatBool(ok),
+		).Inc()
+	}
+}
+
+func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	o := db.f.begin(Exec, db.f.parseLabels(ctx, vs))
+	err := db.db.Exec(ctx, stmt, vs...)
+
+	o.end(err)
+
+	return err
+}
+
+func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
+	l := db.f.parseLabels(ctx, vs)
+
+	return cql.LazyCASScanner(func() (cql.CASScanner, func(bool, error), error) {
+		o := db.f.begin(ExecCAS, l)
+
+		return db.db.ExecCAS(ctx, stmt, vs...), o.endCAS, nil
+	})
+}
+
+func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
+	l := db.f.parseLabels(ctx, vs)
+
+	return cql.LazyScanner(func() (cql.Scanner, func(error), error) {
+		o := db.f.begin(QueryRow, l)
+
+		return db.db.QueryRow(ctx, stmt, vs...), o.end, nil
+	})
+}
+
+type cursor struct {
+	cql.Cursor
+
+	o observation
+
+	scanned uint32
+}
+
+func (c *cursor) Scan(vs ...interface{}) bool {
+	ok := c.Cursor.Scan(vs...)
+
+	if ok {
+		atomic.AddUint32(&c.scanned, 1)
+	}
+
+	return ok
+}
+
+func (c *cursor) MapScan(m map[string]interface{}) bool {
+	ok := cql.CursorMapScan(c.Cursor, m)
+
+	if ok {
+		atomic.AddUint32(&c.scanned, 1)
+	}
+
+	return ok
+}
+
+func (c *cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c *cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
+func (c *cursor) Close() error {
+	err := c.Cursor.Close()
+
+	c.o.end(err)
+	c.o.f.rows.WithLabelValues(c.o.l.query).Observe(
+		float64(atomic.LoadUint32(&c.scanned)),
+	)
+
+	return err
+}
+
+func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
+	o := db.f.begin(Query, db.f.parseLabels(ctx, vs))
+
+	return &cursor{Cursor: db.db.Query(ctx, stmt, vs...), o: o}
+}
+
+type batch struct {
+	cql.Batch
+
+	f *factory
+	l labels
+}
+
+func (b *batch) Exec() error {
+	o := b.f.begin(BatchExec, b.l)
+	err := b.Batch.Exec()
+
+	o.end(err)
+
+	return err
+}
+
+func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
+	o := b.f.begin(BatchExecCAS, b.l)
+	ok, cur, err := b.Batch.ExecCAS()
+
+	o.endCAS(ok, err)
+
+	return ok, cur, err
+}
+
+func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	vs := cql.OptionValues(opts)
+
+	l := db.f.parseLabels(ctx, vs)
+
+	if l.query == unnamedQuery {
+		l.query = batchQuery
+	}
+
+	return &batch{Batch: db.db.Batch(ctx, bt, opts...), f: db.f, l: l}
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
+
+func TestMiddleware(t *testing.T) {
+	var (
+		reg = prometheus.NewPedanticRegistry()
+		mdb = &cqltest.MockDB{
+			Rows: 3,
+			ExecFunc: func(context.Context, string, ...interface{}) error {
+				return &cql.Error{Class: cql.ErrUnavailable, Err: errors.New("boom")}
+			},
+		}
+		db   = NewFactory(reg).Wrap(mdb)
+		ctx  = context.Background()
+		name = cql.NamedQuery("fetch_users")
+	)
+
+	assert.Error(t, db.Exec(ctx, "INSERT", cql.WithConsistency(cql.One)))
+
+	ok, err := db.ExecCAS(ctx, "UPDATE", name).ScanCAS()
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	// Scanners dropped without being scanned are not accounted.
+	db.QueryRow(ctx, "SELECT", name)
+	db.ExecCAS(ctx, "UPDATE", name)
+
+	cur := db.Query(ctx, "SELECT", name)
+
+	for cur.Scan() {
+	}
+
+	assert.NoError(t, cur.Close())
+
+	// A second factory on the same registry shares the collectors.
+	f := NewFactory(reg).(*factory)
+
+	assert.Equal(
+		t,
+		1.0,
+		testutil.ToFloat64(f.cas.WithLabelValues("ExecCAS", "fetch_users", "false")),
+	)
+
+	assert.NoError(
+		t,
+		testutil.GatherAndCompare(
+			reg,
+			strings.NewReader(`
+# HELP cql_cursor_scanned_rows Number of rows scanned per cursor
+# TYPE cql_cursor_scanned_rows histogram
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="1"} 0
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="4"} 1
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="16"} 1
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="64"} 1
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="256"} 1
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="1024"} 1
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="4096"} 1
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="16384"} 1
+cql_cursor_scanned_rows_bucket{query="fetch_users",le="+Inf"} 1
+cql_cursor_scanned_rows_sum{query="fetch_users"} 3
+cql_cursor_scanned_rows_count{query="fetch_users"} 1
+# HELP cql_inflight_operations Number of operations in flight
+# TYPE cql_inflight_operations gauge
+cql_inflight_operations{op="Exec"} 0
+cql_inflight_operations{op="ExecCAS"} 0
+cql_inflight_operations{op="Query"} 0
+`),
+			"cql_cursor_scanned_rows",
+			"cql_inflight_operations",
+		),
+	)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(f.duration))
+
+	for _, labels := range [][]string{
+		{"Exec", "unnamed", "One", "unavailable"},
+		{"ExecCAS", "fetch_users", "default", "none"},
+		{"Query", "fetch_users", "default", "none"},
+	} {
+		var m dto.Metric
+
+		h := f.duration.WithLabelValues(labels...).(prometheus.Histogram)
+
+		assert.NoError(t, h.Write(&m))
+		assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount(), labels)
+	}
+}
+
+func TestTagLabels(t *testing.T) {
+	var (
+		reg = prometheus.NewPedanticRegistry()
+		db  = NewFactory(reg, WithTagLabels("tenant")).Wrap(&cqltest.MockDB{})
+		ctx = cql.ContextWithOptions(
+			cql.ContextWithTags(context.Background(), "tenant", "x"),
+			cql.NamedQuery("insert_user"),
+		)
+	)
+
+	assert.NoError(t, db.Exec(ctx, "INSERT"))
+	assert.NoError(t, db.Exec(context.Background(), "INSERT"))
+
+	f := NewFactory(reg, WithTagLabels("tenant")).(*factory)
+
+	for _, labels := range [][]string{
+		{"Exec", "insert_user", "default", "none", "x"},
+		{"Exec", "unnamed", "default", "none", ""},
+	} {
+		var m dto.Metric
+
+		h := f.duration.WithLabelValues(labels...).(prometheus.Histogram)
+
+		assert.NoError(t, h.Write(&m))
+		assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount(), labels)
+	}
+}
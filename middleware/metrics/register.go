@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// register registers c on reg, the collector already registered by another
+// middleware sharing reg is returned instead.
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+
+		if errors.As(err, &are) {
+			if ec, ok := are.ExistingCollector.(T); ok {
+				return ec
+			}
+		}
+
+		panic(err)
+	}
+
+	return c
+}
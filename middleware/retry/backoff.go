@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+type Backoff interface {
+	// Backoff returns the delay to wait before the n-th retry, n starts at 1,
+	// false means no retry should be attempted anymore.
+	Backoff(n int) (time.Duration, bool)
+}
+
+type ConstantBackoff struct {
+	Delay   time.Duration
+	Retries int
+}
+
+func (cb ConstantBackoff) Backoff(n int) (time.Duration, bool) {
+	if n > cb.Retries {
+		return 0, false
+	}
+
+	return cb.Delay, true
+}
+
+// ExponentialBackoff doubles the delay at each retry, starting at Base and
+// capped at Max. The actual delay is picked at random between half and the
+// totality of it to spread the retries of concurrent callers.
+type ExponentialBackoff struct {
+	Base    time.Duration
+	Max     time.Duration
+	Retries int
+}
+
+func (eb ExponentialBackoff) Backoff(n int) (time.Duration, bool) {
+	if n > eb.Retries {
+		return 0, false
+	}
+
+	d := eb.Base
+
+	for i := 1; i < n && (eb.Max <= 0 || d < eb.Max); i++ {
+		d *= 2
+	}
+
+	if eb.Max > 0 && d > eb.Max {
+		d = eb.Max
+	}
+
+	if d <= 1 {
+		return d, true
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
@@ -0,0 +1,32 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{
+		Base:    10 * time.Millisecond,
+		Max:     50 * time.Millisecond,
+		Retries: 4,
+	}
+
+	for n, want := range []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		50 * time.Millisecond,
+	} {
+		d, ok := b.Backoff(n + 1)
+
+		assert.True(t, ok)
+		assert.LessOrEqual(t, d, want)
+		assert.GreaterOrEqual(t, d, want/2)
+	}
+
+	_, ok := b.Backoff(5)
+	assert.False(t, ok)
+}
@@ -0,0 +1,393 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/upfluence/cql"
+)
+
+var defaultBackoff = ExponentialBackoff{
+	Base:    50 * time.Millisecond,
+	Max:     time.Second,
+	Retries: 3,
+}
+
+// Rule tells whether an operation failing with the given error can be
+// retried.
+type Rule func(error) bool
+
+// RetryClasses returns a rule retrying the errors belonging to one of the
+// given classes, see cql.ErrorClass.
+func RetryClasses(classes ...error) Rule {
+	return func(err error) bool {
+		for _, c := range classes {
+			if errors.Is(err, c) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// DefaultRule retries the failures which are guaranteed to leave no side
+// effect or are transient. Write timeouts are not retried as the write may
+// have been applied.
+var DefaultRule = RetryClasses(
+	cql.ErrReadTimeout,
+	cql.ErrUnavailable,
+	cql.ErrOverloaded,
+)
+
+type Option func(*factory)
+
+func WithBackoff(b Backoff) Option {
+	return func(f *factory) { f.backoff = b }
+}
+
+func WithRule(r Rule) Option {
+	return func(f *factory) { f.rule = r }
+}
+
+// RetryNonIdempotent retries the statements which are not flagged with
+// cql.WithIdempotency(true).
+var RetryNonIdempotent Option = func(f *factory) { f.nonIdempotent = true }
+
+// RetryLWT retries the lightweight transactions, ExecCAS and batch ExecCAS
+// calls, as long as they are flagged as idempotent.
+var RetryLWT Option = func(f *factory) { f.lwt = true }
+
+type factory struct {
+	backoff Backoff
+	rule    Rule
+
+	nonIdempotent bool
+	lwt           bool
+}
+
+func NewFactory(opts ...Option) cql.MiddlewareFactory {
+	f := factory{backoff: defaultBackoff, rule: DefaultRule}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return &f
+}
+
+func (f *factory) Wrap(db cql.DB) cql.DB {
+	return &DB{db: db, f: f}
+}
+
+type DB struct {
+	db cql.DB
+	f  *factory
+}
+
+func (db *DB) Unwrap() cql.DB { return db.db }
+
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
+}
+
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+func isIdempotent(vs []interface{}) bool {
+	var ok bool
+
+	for _, v := range vs {
+		if i, isIdem := v.(cql.WithIdempotency); isIdem {
+			ok = bool(i)
+		}
+	}
+
+	return ok
+}
+
+// retrier tracks the attempts of a single operation.
+type retrier struct {
+	f   *factory
+	ctx context.Context
+
+	enabled bool
+	n       int
+}
+
+func (f *factory) retrier(ctx context.Context, enabled bool) *retrier {
+	return &retrier{f: f, ctx: ctx, enabled: enabled}
+}
+
+// retry returns true once the backoff delay is elapsed if err is eligible for
+// another attempt.
+func (r *retrier) retry(err error) bool {
+	if err == nil || !r.enabled || errors.Is(err, cql.ErrNoRows) || !r.f.rule(err) {
+		return false
+	}
+
+	if r.ctx.Err() != nil {
+		return false
+	}
+
+	r.n++
+
+	d, ok := r.f.backoff.Backoff(r.n)
+
+	if !ok {
+		return false
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-r.ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+func (db *DB) writeRetrier(ctx context.Context, vs []interface{}, lwt bool) *retrier {
+	enabled := db.f.nonIdempotent || isIdempotent(cql.WithContextOptions(ctx, vs))
+
+	if lwt && !db.f.lwt {
+		enabled = false
+	}
+
+	return db.f.retrier(ctx, enabled)
+}
+
+func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	r := db.writeRetrier(ctx, vs, false)
+
+	for {
+		err := db.db.Exec(ctx, stmt, vs...)
+
+		if !r.retry(err) {
+			return err
+		}
+	}
+}
+
+type casScanner struct {
+	db   *DB
+	ctx  context.Context
+	stmt string
+	vs   []interface{}
+}
+
+func (csc casScanner) ScanCAS(vs ...interface{}) (bool, error) {
+	r := csc.db.writeRetrier(csc.ctx, csc.vs, true)
+
+	for {
+		ok, err := csc.db.db.ExecCAS(csc.ctx, csc.stmt, csc.vs...).ScanCAS(vs...)
+
+		if !r.retry(err) {
+			return ok, err
+		}
+	}
+}
+
+func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
+	return casScanner{db: db, ctx: ctx, stmt: stmt, vs: vs}
+}
+
+type scanner struct {
+	cql.Scanner
+
+	db   *DB
+	ctx  context.Context
+	stmt string
+	vs   []interface{}
+}
+
+func (sc *scanner) Columns() []cql.ColumnInfo {
+	return cql.Columns(sc.Scanner)
+}
+
+func (sc *scanner) scan(fn func(cql.Scanner) error) error {
+	r := sc.db.f.retrier(sc.ctx, true)
+
+	for {
+		err := fn(sc.Scanner)
+
+		if !r.retry(err) {
+			return err
+		}
+
+		sc.Scanner = sc.db.db.QueryRow(sc.ctx, sc.stmt, sc.vs...)
+	}
+}
+
+func (sc *scanner) Scan(vs ...interface{}) error {
+	return sc.scan(func(s cql.Scanner) error { return s.Scan(vs...) })
+}
+
+func (sc *scanner) MapScan(m map[string]interface{}) error {
+	return sc.scan(func(s cql.Scanner) error { return cql.MapScan(s, m) })
+}
+
+// QueryRow retries the read when the row is scanned, reads are considered
+// idempotent.
+func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
+	return &scanner{
+		Scanner: db.db.QueryRow(ctx, stmt, vs...),
+		db:      db,
+		ctx:     ctx,
+		stmt:    stmt,
+		vs:      vs,
+	}
+}
+
+type cursor struct {
+	cql.Cursor
+
+	db   *DB
+	ctx  context.Context
+	stmt string
+	vs   []interface{}
+	r    *retrier
+
+	scanned bool
+	closed  bool
+	err     error
+}
+
+func (c *cursor) scan(fn func(cql.Cursor) bool) bool {
+	for {
+		if c.closed {
+			return false
+		}
+
+		if fn(c.Cursor) {
+			c.scanned = true
+			return true
+		}
+
+		if c.scanned {
+			return false
+		}
+
+		c.err = c.Cursor.Close()
+		c.closed = true
+
+		if !c.r.retry(c.err) {
+			return false
+		}
+
+		c.Cursor = c.db.db.Query(c.ctx, c.stmt, c.vs...)
+		c.closed = false
+		c.err = nil
+	}
+}
+
+func (c *cursor) Scan(vs ...interface{}) bool {
+	return c.scan(func(cc cql.Cursor) bool { return cc.Scan(vs...) })
+}
+
+func (c *cursor) MapScan(m map[string]interface{}) bool {
+	return c.scan(func(cc cql.Cursor) bool { return cql.CursorMapScan(cc, m) })
+}
+
+func (c *cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c *cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
+func (c *cursor) Close() error {
+	if c.closed {
+		return c.err
+	}
+
+	c.closed = true
+
+	return c.Cursor.Close()
+}
+
+// Query re-runs the query only as long as no row has been consumed from the
+// cursor, once a row is scanned the failures are returned as is by Close.
+func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
+	return &cursor{
+		Cursor: db.db.Query(ctx, stmt, vs...),
+		db:     db,
+		ctx:    ctx,
+		stmt:   stmt,
+		vs:     vs,
+		r:      db.f.retrier(ctx, true),
+	}
+}
+
+type query struct {
+	stmt string
+	vs   []interface{}
+}
+
+type batch struct {
+	db  *DB
+	ctx context.Context
+
+	bt      cql.BatchType
+	opts    []cql.Option
+	queries []query
+}
+
+func (b *batch) Query(stmt string, vs ...interface{}) {
+	b.queries = append(b.queries, query{stmt: stmt, vs: vs})
+}
+
+func (b *batch) build() cql.Batch {
+	cb := b.db.db.Batch(b.ctx, b.bt, b.opts...)
+
+	for _, q := range b.queries {
+		cb.Query(q.stmt, q.vs...)
+	}
+
+	return cb
+}
+
+func (b *batch) retrier(lwt bool) *retrier {
+	vs := cql.OptionValues(b.opts)
+
+	return b.db.writeRetrier(b.ctx, vs, lwt)
+}
+
+func (b *batch) Exec() error {
+	r := b.retrier(false)
+
+	for {
+		err := b.build().Exec()
+
+		if !r.retry(err) {
+			return err
+		}
+	}
+}
+
+func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
+	r := b.retrier(true)
+
+	for {
+		ok, cur, err := b.build().ExecCAS()
+
+		if !r.retry(err) {
+			return ok, cur, err
+		}
+	}
+}
+
+// Batch buffers the queries so the batch can be built again from scratch for
+// each attempt.
+func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	return &batch{db: db, ctx: ctx, bt: bt, opts: opts}
+}
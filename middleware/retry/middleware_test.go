@@ -0,0 +1,153 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
+
+var (
+	errRead  = &cql.Error{Class: cql.ErrReadTimeout, Err: errors.New("read")}
+	errWrite = &cql.Error{Class: cql.ErrWriteTimeout, Err: errors.New("write")}
+)
+
+func TestMiddleware(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		opts = []Option{WithBackoff(ConstantBackoff{Retries: 2})}
+	)
+
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		errs []error
+		fn   func(cql.DB) error
+
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "non idempotent exec",
+			errs:      []error{errRead},
+			fn:        func(db cql.DB) error { return db.Exec(ctx, "INSERT") },
+			wantErr:   errRead,
+			wantCalls: 1,
+		},
+		{
+			name: "idempotent exec",
+			errs: []error{errRead, errRead},
+			fn: func(db cql.DB) error {
+				return db.Exec(ctx, "INSERT", cql.Idempotent)
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "idempotent exec exhausted",
+			errs: []error{errRead, errRead, errRead},
+			fn: func(db cql.DB) error {
+				return db.Exec(ctx, "INSERT", cql.Idempotent)
+			},
+			wantErr:   errRead,
+			wantCalls: 3,
+		},
+		{
+			name: "write timeout",
+			errs: []error{errWrite},
+			fn: func(db cql.DB) error {
+				return db.Exec(ctx, "INSERT", cql.Idempotent)
+			},
+			wantErr:   errWrite,
+			wantCalls: 1,
+		},
+		{
+			name: "custom rule",
+			opts: []Option{WithRule(RetryClasses(cql.ErrWriteTimeout))},
+			errs: []error{errWrite},
+			fn: func(db cql.DB) error {
+				return db.Exec(ctx, "INSERT", cql.Idempotent)
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "lwt",
+			errs: []error{errRead},
+			fn: func(db cql.DB) error {
+				_, err := db.ExecCAS(ctx, "INSERT", cql.Idempotent).ScanCAS()
+				return err
+			},
+			wantErr:   errRead,
+			wantCalls: 1,
+		},
+		{
+			name: "lwt allowed",
+			opts: []Option{RetryLWT},
+			errs: []error{errRead},
+			fn: func(db cql.DB) error {
+				_, err := db.ExecCAS(ctx, "INSERT", cql.Idempotent).ScanCAS()
+				return err
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "query row",
+			errs:      []error{errRead},
+			fn:        func(db cql.DB) error { return db.QueryRow(ctx, "SELECT").Scan() },
+			wantCalls: 2,
+		},
+		{
+			name:      "query row no rows",
+			errs:      []error{cql.ErrNoRows},
+			fn:        func(db cql.DB) error { return db.QueryRow(ctx, "SELECT").Scan() },
+			wantErr:   cql.ErrNoRows,
+			wantCalls: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mdb = &cqltest.MockDB{Errs: tt.errs}
+				db  = NewFactory(append(opts, tt.opts...)...).Wrap(mdb)
+			)
+
+			err := tt.fn(db)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantCalls, mdb.Calls())
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	var (
+		ctx = context.Background()
+		mdb = &cqltest.MockDB{
+			Cursors: []*cqltest.MockCursor{
+				{Err: errRead},
+				{Rows: 2, Err: errRead},
+				{Rows: 2},
+			},
+		}
+		db = NewFactory(WithBackoff(ConstantBackoff{Retries: 2})).Wrap(mdb)
+	)
+
+	cur := db.Query(ctx, "SELECT")
+
+	var n int
+
+	for cur.Scan() {
+		n++
+	}
+
+	assert.Equal(t, 2, n)
+	assert.ErrorIs(t, cur.Close(), errRead)
+	assert.Equal(t, 2, mdb.Calls())
+}
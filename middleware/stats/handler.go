@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/upfluence/errors"
+
+	"github.com/upfluence/cql"
+)
+
+const defaultTopN = 20
+
+var ErrNotInChain = errors.New("Stats middleware is not part of the chain")
+
+type handler struct {
+	db *DB
+}
+
+// NewHandler returns an http.Handler listing the top queries by total time
+// as JSON, the number of queries is set by the n query parameter, 0 lists
+// them all. It fails with ErrNotInChain if the stats middleware is not part
+// of the chain of db.
+func NewHandler(db cql.DB) (http.Handler, error) {
+	var sdb *DB
+
+	if !cql.As(db, &sdb) {
+		return nil, ErrNotInChain
+	}
+
+	return &handler{db: sdb}, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := defaultTopN
+
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+
+		if n, err = strconv.Atoi(v); err != nil || n < 0 {
+			http.Error(w, "invalid n parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ss := h.db.Snapshot()
+
+	if n > 0 && len(ss) > n {
+		ss = ss[:n]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(ss)
+}
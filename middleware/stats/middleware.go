@@ -0,0 +1,356 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqlfingerprint"
+	"github.com/upfluence/cql/internal/lru"
+)
+
+const (
+	defaultMaxEntries = 1000
+	defaultSamples    = 1024
+
+	fingerprintCacheSize = 4096
+
+	// OverflowFingerprint gathers the statements seen once the maximum
+	// number of fingerprints is reached.
+	OverflowFingerprint = "<overflow>"
+)
+
+type Option func(*factory)
+
+// WithMaxEntries caps the number of fingerprints tracked.
+func WithMaxEntries(n int) Option {
+	return func(f *factory) { f.maxEntries = n }
+}
+
+// WithSamples sets the number of durations kept per fingerprint to compute
+// the percentiles, they are computed over the most recent calls.
+func WithSamples(n int) Option {
+	return func(f *factory) { f.samples = n }
+}
+
+type factory struct {
+	maxEntries int
+	samples    int
+}
+
+func NewFactory(opts ...Option) cql.MiddlewareFactory {
+	f := factory{maxEntries: defaultMaxEntries, samples: defaultSamples}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return &f
+}
+
+func (f *factory) Wrap(db cql.DB) cql.DB {
+	return &DB{
+		db:           db,
+		f:            f,
+		entries:      make(map[string]*entry),
+		fingerprints: lru.New[string, string](fingerprintCacheSize),
+	}
+}
+
+type DB struct {
+	db cql.DB
+	f  *factory
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+
+	fingerprints *lru.Cache[string, string]
+}
+
+func (db *DB) Unwrap() cql.DB { return db.db }
+
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
+}
+
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+// Snapshot returns the statistics of every fingerprint, sorted by
+// decreasing total time.
+func (db *DB) Snapshot() []Stat {
+	db.mu.RLock()
+
+	es := make([]*entry, 0, len(db.entries))
+
+	for _, e := range db.entries {
+		es = append(es, e)
+	}
+
+	db.mu.RUnlock()
+
+	ss := make([]Stat, len(es))
+
+	for i, e := range es {
+		ss[i] = e.snapshot()
+	}
+
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].TotalTime == ss[j].TotalTime {
+			return ss[i].Fingerprint < ss[j].Fingerprint
+		}
+
+		return ss[i].TotalTime > ss[j].TotalTime
+	})
+
+	return ss
+}
+
+func (db *DB) Reset() {
+	db.mu.Lock()
+	db.entries = make(map[string]*entry)
+	db.mu.Unlock()
+}
+
+func namedQuery(ctx context.Context, vs []interface{}) string {
+	nq, _ := cql.LookupNamedQuery(cql.WithContextOptions(ctx, vs))
+
+	return nq
+}
+
+func (db *DB) entry(fp, query string) *entry {
+	db.mu.RLock()
+	e, ok := db.entries[fp]
+	db.mu.RUnlock()
+
+	if ok {
+		return e
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if e, ok := db.entries[fp]; ok {
+		return e
+	}
+
+	if len(db.entries) >= db.f.maxEntries {
+		fp, query = OverflowFingerprint, ""
+
+		if e, ok := db.entries[fp]; ok {
+			return e
+		}
+	}
+
+	e = newEntry(fp, query, db.f.samples)
+	db.entries[fp] = e
+
+	return e
+}
+
+type observation struct {
+	db    *DB
+	fp    string
+	query string
+	t0    time.Time
+}
+
+func (db *DB) fingerprint(stmt string) string {
+	return db.fingerprints.GetOrAdd(stmt, cqlfingerprint.Fingerprint)
+}
+
+func (db *DB) begin(ctx context.Context, fp string, vs []interface{}) observation {
+	return observation{db: db, fp: fp, query: namedQuery(ctx, vs), t0: time.Now()}
+}
+
+func (o observation) end(rows int64, err error) {
+	if errors.Is(err, cql.ErrNoRows) {
+		err = nil
+	}
+
+	now := time.Now()
+
+	o.db.entry(o.fp, o.query).observe(now.Sub(o.t0), rows, err, now)
+}
+
+func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	o := db.begin(ctx, db.fingerprint(stmt), vs)
+	err := db.db.Exec(ctx, stmt, vs...)
+
+	o.end(0, err)
+
+	return err
+}
+
+type casScanner struct {
+	cql.CASScanner
+
+	o observation
+}
+
+func (csc casScanner) ScanCAS(vs ...interface{}) (bool, error) {
+	ok, err := csc.CASScanner.ScanCAS(vs...)
+
+	csc.o.end(0, err)
+
+	return ok, err
+}
+
+func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
+	o := db.begin(ctx, db.fingerprint(stmt), vs)
+
+	return casScanner{CASScanner: db.db.ExecCAS(ctx, stmt, vs...), o: o}
+}
+
+type scanner struct {
+	cql.Scanner
+
+	o observation
+}
+
+func (sc scanner) Columns() []cql.ColumnInfo {
+	return cql.Columns(sc.Scanner)
+}
+
+func (sc scanner) end(err error) error {
+	var rows int64
+
+	if err == nil {
+		rows = 1
+	}
+
+	sc.o.end(rows, err)
+
+	return err
+}
+
+func (sc scanner) Scan(vs ...interface{}) error {
+	return sc.end(sc.Scanner.Scan(vs...))
+}
+
+func (sc scanner) MapScan(m map[string]interface{}) error {
+	return sc.end(cql.MapScan(sc.Scanner, m))
+}
+
+func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
+	o := db.begin(ctx, db.fingerprint(stmt), vs)
+
+	return scanner{Scanner: db.db.QueryRow(ctx, stmt, vs...), o: o}
+}
+
+type cursor struct {
+	cql.Cursor
+
+	o observation
+
+	scanned int64
+}
+
+func (c *cursor) Scan(vs ...interface{}) bool {
+	ok := c.Cursor.Scan(vs...)
+
+	if ok {
+		atomic.AddInt64(&c.scanned, 1)
+	}
+
+	return ok
+}
+
+func (c *cursor) MapScan(m map[string]interface{}) bool {
+	ok := cql.CursorMapScan(c.Cursor, m)
+
+	if ok {
+		atomic.AddInt64(&c.scanned, 1)
+	}
+
+	return ok
+}
+
+func (c *cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c *cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
+func (c *cursor) Close() error {
+	err := c.Cursor.Close()
+
+	c.o.end(atomic.LoadInt64(&c.scanned), err)
+
+	return err
+}
+
+func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
+	o := db.begin(ctx, db.fingerprint(stmt), vs)
+
+	return &cursor{Cursor: db.db.Query(ctx, stmt, vs...), o: o}
+}
+
+type batch struct {
+	cql.Batch
+
+	db   *DB
+	ctx  context.Context
+	bt   cql.BatchType
+	opts []interface{}
+
+	fps  []string
+	seen map[string]struct{}
+}
+
+// Query collapses the statements sharing a fingerprint, so the batches
+// running the same statements share a fingerprint whatever their size.
+func (b *batch) Query(stmt string, vs ...interface{}) {
+	fp := b.db.fingerprint(stmt)
+
+	if _, ok := b.seen[fp]; !ok {
+		b.seen[fp] = struct{}{}
+		b.fps = append(b.fps, fp)
+	}
+
+	b.Batch.Query(stmt, vs...)
+}
+
+func (b *batch) begin() observation {
+	return b.db.begin(b.ctx, cql.BatchStatement(b.bt, b.fps), b.opts)
+}
+
+func (b *batch) Exec() error {
+	o := b.begin()
+	err := b.Batch.Exec()
+
+	o.end(0, err)
+
+	return err
+}
+
+func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
+	o := b.begin()
+	ok, cur, err := b.Batch.ExecCAS()
+
+	o.end(0, err)
+
+	return ok, cur, err
+}
+
+func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	return &batch{
+		Batch: db.db.Batch(ctx, bt, opts...),
+		db:    db,
+		ctx:   ctx,
+		bt:    bt,
+		opts:  cql.OptionValues(opts),
+		seen:  make(map[string]struct{}),
+	}
+}
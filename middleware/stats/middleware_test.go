@@ -0,0 +1,136 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
+
+func TestStats(t *testing.T) {
+	var (
+		mdb = cqltest.MockDB{Rows: 3}
+		ctx = context.Background()
+		db  = cql.Chain(NewFactory(WithSamples(2))).Wrap(&mdb)
+	)
+
+	for _, id := range []string{"1", "2"} {
+		assert.NoError(
+			t,
+			db.Exec(
+				ctx,
+				"UPDATE users SET name = 'foo' WHERE id IN ("+id+", 3)",
+				cql.NamedQuery("rename"),
+			),
+		)
+	}
+
+	mdb.Err = errors.New("boom")
+	assert.Error(t, db.Exec(ctx, "UPDATE users SET name = 'bar' WHERE id IN (4)"))
+
+	cur := db.Query(ctx, "SELECT * FROM users LIMIT 10")
+
+	for cur.Scan() {
+	}
+
+	require.NoError(t, cur.Close())
+
+	var sdb *DB
+
+	require.True(t, cql.As(db, &sdb))
+
+	ss := sdb.Snapshot()
+	require.Len(t, ss, 2)
+
+	byFp := map[string]Stat{ss[0].Fingerprint: ss[0], ss[1].Fingerprint: ss[1]}
+
+	upd := byFp["UPDATE users SET name = ? WHERE id IN (?)"]
+	assert.Equal(t, "rename", upd.Query)
+	assert.Equal(t, int64(3), upd.Calls)
+	assert.Equal(t, int64(1), upd.Errors)
+	assert.Equal(t, "boom", upd.LastError)
+	assert.LessOrEqual(t, upd.MinTime, upd.P50)
+	assert.LessOrEqual(t, upd.P99, upd.MaxTime)
+
+	sel := byFp["SELECT * FROM users LIMIT ?"]
+	assert.Equal(t, int64(1), sel.Calls)
+	assert.Equal(t, int64(3), sel.Rows)
+
+	rec := httptest.NewRecorder()
+	h, err := NewHandler(db)
+	require.NoError(t, err)
+
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/?n=1", nil))
+
+	var hss []Stat
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&hss))
+	require.Len(t, hss, 1)
+	assert.Equal(t, ss[0].Fingerprint, hss[0].Fingerprint)
+	assert.Equal(t, ss[0].TotalTime, hss[0].TotalTime)
+
+	sdb.Reset()
+	assert.Empty(t, sdb.Snapshot())
+
+	_, err = NewHandler(&mdb)
+	assert.ErrorIs(t, err, ErrNotInChain)
+}
+
+func TestMaxEntries(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = NewFactory(WithMaxEntries(1)).Wrap(&cqltest.MockDB{}).(*DB)
+	)
+
+	assert.NoError(t, db.Exec(ctx, "DELETE FROM users WHERE id = ?", 1))
+	assert.NoError(t, db.Exec(ctx, "DELETE FROM posts WHERE id = ?", 1))
+	assert.NoError(t, db.Exec(ctx, "DELETE FROM likes WHERE id = ?", 1))
+
+	var fps []string
+
+	for _, s := range db.Snapshot() {
+		fps = append(fps, s.Fingerprint)
+	}
+
+	assert.ElementsMatch(
+		t,
+		[]string{"DELETE FROM users WHERE id = ?", OverflowFingerprint},
+		fps,
+	)
+}
+
+func TestBatchFingerprint(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = NewFactory().Wrap(&cqltest.MockDB{}).(*DB)
+	)
+
+	for _, n := range []int{1, 3} {
+		b := db.Batch(ctx, cql.UnloggedBatch)
+
+		for i := 0; i < n; i++ {
+			b.Query("INSERT INTO users(id) VALUES (?)", i)
+			b.Query("INSERT INTO emails(id) VALUES (?)", i)
+		}
+
+		require.NoError(t, b.Exec())
+	}
+
+	ss := db.Snapshot()
+	require.Len(t, ss, 1)
+	assert.Equal(
+		t,
+		"BEGIN UNLOGGED BATCH INSERT INTO users(id) VALUES (?); INSERT INTO emails(id) VALUES (?); APPLY BATCH",
+		ss[0].Fingerprint,
+	)
+	assert.Equal(t, int64(2), ss[0].Calls)
+}
@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stat is the snapshot of the statistics of the statements sharing a
+// fingerprint.
+type Stat struct {
+	Fingerprint string `json:"fingerprint"`
+	Query       string `json:"query,omitempty"`
+
+	Calls  int64 `json:"calls"`
+	Errors int64 `json:"errors"`
+	Rows   int64 `json:"rows"`
+
+	TotalTime time.Duration `json:"total_time"`
+	MinTime   time.Duration `json:"min_time"`
+	MaxTime   time.Duration `json:"max_time"`
+	MeanTime  time.Duration `json:"mean_time"`
+	P50       time.Duration `json:"p50"`
+	P95       time.Duration `json:"p95"`
+	P99       time.Duration `json:"p99"`
+
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at"`
+}
+
+type entry struct {
+	mu sync.Mutex
+
+	stat Stat
+
+	// samples keeps the last durations observed to compute the
+	// percentiles, next is the index the next one is written at.
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+func newEntry(fp, query string, size int) *entry {
+	return &entry{
+		stat:    Stat{Fingerprint: fp, Query: query},
+		samples: make([]time.Duration, size),
+	}
+}
+
+func (e *entry) observe(d time.Duration, rows int64, err error, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := &e.stat
+
+	if s.Calls == 0 || d < s.MinTime {
+		s.MinTime = d
+	}
+
+	if d > s.MaxTime {
+		s.MaxTime = d
+	}
+
+	s.Calls++
+	s.Rows += rows
+	s.TotalTime += d
+
+	if err != nil {
+		s.Errors++
+		s.LastError = err.Error()
+		s.LastErrorAt = now
+	}
+
+	if len(e.samples) == 0 {
+		return
+	}
+
+	e.samples[e.next] = d
+	e.next++
+
+	if e.next == len(e.samples) {
+		e.next = 0
+		e.full = true
+	}
+}
+
+func percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+
+	return ds[int(p*float64(len(ds)-1)+0.5)]
+}
+
+func (e *entry) snapshot() Stat {
+	e.mu.Lock()
+
+	var (
+		s  = e.stat
+		ds []time.Duration
+	)
+
+	if e.full {
+		ds = append(ds, e.samples...)
+	} else {
+		ds = append(ds, e.samples[:e.next]...)
+	}
+
+	e.mu.Unlock()
+
+	if s.Calls > 0 {
+		s.MeanTime = s.TotalTime / time.Duration(s.Calls)
+	}
+
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+
+	s.P50 = percentile(ds, .5)
+	s.P95 = percentile(ds, .95)
+	s.P99 = percentile(ds, .99)
+
+	return s
+}
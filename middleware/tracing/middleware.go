@@ -0,0 +1,297 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/upfluence/cql"
+)
+
+const (
+	tracerName = "github.com/upfluence/cql/middleware/tracing"
+
+	tagKeyPrefix = "cql.tag."
+)
+
+var (
+	rowsScannedKey = attribute.Key("db.cassandra.rows_scanned")
+	appliedKey     = attribute.Key("db.cassandra.applied")
+	batchSizeKey   = attribute.Key("db.cassandra.batch.size")
+	batchTypeKey   = attribute.Key("db.cassandra.batch.type")
+
+	consistencies = map[cql.Consistency]attribute.KeyValue{
+		cql.Any:         semconv.DBCassandraConsistencyLevelAny,
+		cql.One:         semconv.DBCassandraConsistencyLevelOne,
+		cql.Two:         semconv.DBCassandraConsistencyLevelTwo,
+		cql.Three:       semconv.DBCassandraConsistencyLevelThree,
+		cql.Quorum:      semconv.DBCassandraConsistencyLevelQuorum,
+		cql.All:         semconv.DBCassandraConsistencyLevelAll,
+		cql.LocalQuorum: semconv.DBCassandraConsistencyLevelLocalQuorum,
+		cql.EachQuorum:  semconv.DBCassandraConsistencyLevelEachQuorum,
+		cql.LocalOne:    semconv.DBCassandraConsistencyLevelLocalOne,
+	}
+)
+
+type Option func(*factory)
+
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(f *factory) { f.tp = tp }
+}
+
+// WithKeyspace sets the db.name attribute of every span.
+func WithKeyspace(ks string) Option {
+	return func(f *factory) { f.keyspace = ks }
+}
+
+type factory struct {
+	tp       trace.TracerProvider
+	keyspace string
+}
+
+// NewFactory returns a middleware factory opening a span per operation, the
+// global tracer provider is used unless WithTracerProvider is given.
+func NewFactory(opts ...Option) cql.MiddlewareFactory {
+	f := factory{tp: otel.GetTracerProvider()}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return &f
+}
+
+func (f *factory) Wrap(db cql.DB) cql.DB {
+	return &DB{db: db, t: f.tp.Tracer(tracerName), keyspace: f.keyspace}
+}
+
+type DB struct {
+	db cql.DB
+	t  trace.Tracer
+
+	keyspace string
+}
+
+func (db *DB) Unwrap() cql.DB { return db.db }
+
+func (db *DB) Close(ctx context.Context) error {
+	return cql.Close(ctx, db.db)
+}
+
+func (db *DB) Ping(ctx context.Context) (cql.Health, error) {
+	return cql.Ping(ctx, db.db)
+}
+
+func (db *DB) ProtoVersion() int {
+	return cql.ProtoVersion(db.db)
+}
+
+func operation(stmt string) string {
+	if fs := strings.Fields(stmt); len(fs) > 0 {
+		return strings.ToUpper(fs[0])
+	}
+
+	return ""
+}
+
+func (db *DB) start(ctx context.Context, op, stmt string, vs []interface{}, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	var name string
+
+	attrs = append(attrs, semconv.DBSystemCassandra)
+
+	if db.keyspace != "" {
+		attrs = append(attrs, semconv.DBName(db.keyspace))
+	}
+
+	if stmt != "" {
+		attrs = append(attrs, semconv.DBStatement(stmt))
+
+		if o := operation(stmt); o != "" {
+			op = o
+			attrs = append(attrs, semconv.DBOperation(o))
+		}
+	}
+
+	for _, t := range cql.ContextTags(ctx) {
+		attrs = append(attrs, attribute.String(tagKeyPrefix+t.Key, t.Value))
+	}
+
+	for _, v := range cql.WithContextOptions(ctx, vs) {
+		switch vv := v.(type) {
+		case cql.NamedQuery:
+			name = string(vv)
+		case cql.WithConsistency:
+			if kv, ok := consistencies[cql.Consistency(vv)]; ok {
+				attrs = append(attrs, kv)
+			}
+		case cql.WithIdempotency:
+			attrs = append(attrs, semconv.DBCassandraIdempotence(bool(vv)))
+		case cql.WithPageSize:
+			attrs = append(attrs, semconv.DBCassandraPageSize(int(vv)))
+		}
+	}
+
+	if name == "" {
+		name = op
+	}
+
+	return db.t.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func end(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	span.SetAttributes(attrs...)
+
+	if err != nil && !errors.Is(err, cql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+func (db *DB) Exec(ctx context.Context, stmt string, vs ...interface{}) error {
+	ctx, span := db.start(ctx, "Exec", stmt, vs)
+	err := db.db.Exec(ctx, stmt, vs...)
+
+	end(span, err)
+
+	return err
+}
+
+func (db *DB) ExecCAS(ctx context.Context, stmt string, vs ...interface{}) cql.CASScanner {
+	return cql.LazyCASScanner(func() (cql.CASScanner, func(bool, error), error) {
+		ctx, span := db.start(ctx, "ExecCAS", stmt, vs)
+		sc := db.db.ExecCAS(ctx, stmt, vs...)
+
+		return sc, func(ok bool, err error) { end(span, err, appliedKey.Bool(ok)) }, nil
+	})
+}
+
+func (db *DB) QueryRow(ctx context.Context, stmt string, vs ...interface{}) cql.Scanner {
+	return cql.LazyScanner(func() (cql.Scanner, func(error), error) {
+		ctx, span := db.start(ctx, "QueryRow", stmt, vs)
+		sc := db.db.QueryRow(ctx, stmt, vs...)
+
+		return sc, func(err error) { end(span, err) }, nil
+	})
+}
+
+type cursor struct {
+	cql.Cursor
+
+	span trace.Span
+
+	scanned int64
+}
+
+func (c *cursor) Scan(vs ...interface{}) bool {
+	ok := c.Cursor.Scan(vs...)
+
+	if ok {
+		atomic.AddInt64(&c.scanned, 1)
+	}
+
+	return ok
+}
+
+func (c *cursor) MapScan(m map[string]interface{}) bool {
+	ok := cql.CursorMapScan(c.Cursor, m)
+
+	if ok {
+		atomic.AddInt64(&c.scanned, 1)
+	}
+
+	return ok
+}
+
+func (c *cursor) Columns() []cql.ColumnInfo {
+	return cql.Columns(c.Cursor)
+}
+
+func (c *cursor) PageState() []byte {
+	return cql.CursorPageState(c.Cursor)
+}
+
+func (c *cursor) Close() error {
+	err := c.Cursor.Close()
+
+	end(c.span, err, rowsScannedKey.Int64(atomic.LoadInt64(&c.scanned)))
+
+	return err
+}
+
+func (db *DB) Query(ctx context.Context, stmt string, vs ...interface{}) cql.Cursor {
+	ctx, span := db.start(ctx, "Query", stmt, vs)
+
+	return &cursor{Cursor: db.db.Query(ctx, stmt, vs...), span: span}
+}
+
+// batch buffers its statements, the span is started once the batch is
+// executed and the inner batch is built with its context.
+type batch struct {
+	db   *DB
+	ctx  context.Context
+	bt   cql.BatchType
+	opts []cql.Option
+
+	stmts []string
+	vs    [][]interface{}
+}
+
+func (b *batch) Query(stmt string, vs ...interface{}) {
+	b.stmts = append(b.stmts, stmt)
+	b.vs = append(b.vs, vs)
+}
+
+func (b *batch) build() (cql.Batch, trace.Span) {
+	ctx, span := b.db.start(
+		b.ctx,
+		"BATCH",
+		"",
+		cql.OptionValues(b.opts),
+		batchTypeKey.String(b.bt.String()),
+		batchSizeKey.Int(len(b.stmts)),
+	)
+
+	cb := b.db.db.Batch(ctx, b.bt, b.opts...)
+
+	for i, stmt := range b.stmts {
+		cb.Query(stmt, b.vs[i]...)
+	}
+
+	return cb, span
+}
+
+func (b *batch) Exec() error {
+	cb, span := b.build()
+	err := cb.Exec()
+
+	end(span, err)
+
+	return err
+}
+
+func (b *batch) ExecCAS() (bool, cql.Cursor, error) {
+	cb, span := b.build()
+	ok, cur, err := cb.ExecCAS()
+
+	end(span, err, appliedKey.Bool(ok))
+
+	return ok, cur, err
+}
+
+func (db *DB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	return &batch{db: db, ctx: ctx, bt: bt, opts: opts}
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
+
+func attributes(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+type batchDB struct {
+	*cqltest.MockDB
+
+	ctx context.Context
+}
+
+func (db *batchDB) Batch(ctx context.Context, bt cql.BatchType, opts ...cql.Option) cql.Batch {
+	db.ctx = ctx
+
+	return db.MockDB.Batch(ctx, bt, opts...)
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		exp = tracetest.NewInMemoryExporter()
+		tp  = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+		mdb = &cqltest.MockDB{
+			Rows:    2,
+			Applied: true,
+			ExecFunc: func(ctx context.Context, _ string, _ ...interface{}) error {
+				if !trace.SpanContextFromContext(ctx).IsValid() {
+					return errors.New("no span in context")
+				}
+
+				return errors.New("boom")
+			},
+		}
+		bdb = &batchDB{MockDB: mdb}
+		db  = NewFactory(WithTracerProvider(tp), WithKeyspace("foo")).Wrap(bdb)
+		ctx = context.Background()
+	)
+
+	assert.EqualError(
+		t,
+		db.Exec(ctx, "insert into users(id) values (?)", 1, cql.NamedQuery("insert_user")),
+		"boom",
+	)
+
+	ok, err := db.ExecCAS(ctx, "UPDATE users SET name = ? IF EXISTS", "bar").ScanCAS()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	cur := db.Query(ctx, "SELECT id FROM users", cql.WithConsistency(cql.LocalQuorum))
+
+	assert.Len(t, exp.GetSpans(), 2)
+
+	for cur.Scan() {
+	}
+
+	require.NoError(t, cur.Close())
+
+	b := db.Batch(ctx, cql.UnloggedBatch)
+	b.Query("INSERT INTO users(id) VALUES (?)", 1)
+	b.Query("INSERT INTO users(id) VALUES (?)", 2)
+	require.NoError(t, b.Exec())
+
+	spans := exp.GetSpans().Snapshots()
+	require.Len(t, spans, 4)
+
+	assert.Equal(t, "insert_user", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "INSERT", attributes(spans[0])["db.operation"].AsString())
+	assert.Equal(t, "foo", attributes(spans[0])["db.name"].AsString())
+	assert.Equal(t, "cassandra", attributes(spans[0])["db.system"].AsString())
+
+	assert.Equal(t, "UPDATE", spans[1].Name())
+	assert.True(t, attributes(spans[1])[appliedKey].AsBool())
+
+	assert.Equal(t, "SELECT", spans[2].Name())
+	assert.Equal(t, int64(2), attributes(spans[2])[rowsScannedKey].AsInt64())
+	assert.Equal(
+		t,
+		"local_quorum",
+		attributes(spans[2])["db.cassandra.consistency_level"].AsString(),
+	)
+	assert.Equal(t, "SELECT id FROM users", attributes(spans[2])["db.statement"].AsString())
+
+	assert.Equal(t, "BATCH", spans[3].Name())
+	assert.Equal(t, int64(2), attributes(spans[3])[batchSizeKey].AsInt64())
+	assert.Equal(t, codes.Unset, spans[3].Status().Code)
+	assert.Equal(t, spans[3].SpanContext(), trace.SpanContextFromContext(bdb.ctx))
+}
+
+func TestUnusedOperations(t *testing.T) {
+	var (
+		sr  = tracetest.NewSpanRecorder()
+		tp  = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+		db  = NewFactory(WithTracerProvider(tp)).Wrap(&cqltest.MockDB{})
+		ctx = context.Background()
+	)
+
+	// Scanners never scanned and batches never executed start no span.
+	db.QueryRow(ctx, "SELECT id FROM users")
+	db.ExecCAS(ctx, "UPDATE users SET name = ? IF EXISTS", "foo")
+	db.Batch(ctx, cql.LoggedBatch).Query("INSERT INTO users(id) VALUES (?)", 1)
+
+	assert.Empty(t, sr.Started())
+
+	require.NoError(t, db.QueryRow(ctx, "SELECT id FROM users").Scan())
+	assert.Len(t, sr.Started(), 1)
+	assert.Len(t, sr.Ended(), 1)
+}
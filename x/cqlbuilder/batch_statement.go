@@ -2,6 +2,7 @@ package cqlbuilder
 
 import (
 	"context"
+	"time"
 
 	"github.com/upfluence/cql"
 )
@@ -9,8 +10,11 @@ import (
 type BatchStatement struct {
 	Type cql.BatchType
 
-	Statements  []CASStatement
-	Consistency cql.Consistency
+	Statements        []CASStatement
+	Consistency       cql.Consistency
+	SerialConsistency cql.SerialConsistency
+	Idempotent        bool
+	Timeout           time.Duration
 }
 
 type BatchExecer struct {
@@ -19,16 +23,17 @@ type BatchExecer struct {
 }
 
 func (be *BatchExecer) Exec(ctx context.Context, qvs map[string]interface{}) error {
-	var opts []cql.Option
-
-	if be.Statement.Consistency > cql.Any {
-		opts = append(opts, cql.WithConsistency(be.Statement.Consistency))
-	}
+	opts := statementOptions{
+		consistency:       be.Statement.Consistency,
+		serialConsistency: be.Statement.SerialConsistency,
+		idempotent:        be.Statement.Idempotent,
+		timeout:           be.Statement.Timeout,
+	}.options()
 
 	b := be.QueryBuilder.Batch(ctx, be.Statement.Type, opts...)
 
 	for _, s := range be.Statement.Statements {
-		stmt, vs, err := s.buildQuery(qvs)
+		stmt, vs, err := s.buildQuery(unsetValues(be.QueryBuilder, s, qvs))
 
 		switch err {
 		case nil:
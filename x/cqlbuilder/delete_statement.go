@@ -20,9 +20,12 @@ type DeleteStatement struct {
 	Fields      []Marker
 	WhereClause PredicateClause
 
-	Timestamp   time.Time
-	LWTClause   LWTDeleteClause
-	Consistency cql.Consistency
+	Timestamp         time.Time
+	LWTClause         LWTDeleteClause
+	Consistency       cql.Consistency
+	SerialConsistency cql.SerialConsistency
+	Idempotent        bool
+	Timeout           time.Duration
 }
 
 func (ds DeleteStatement) casScanKeys() []string {
@@ -68,9 +71,12 @@ func (ds DeleteStatement) buildQuery(qvs map[string]interface{}) (string, []inte
 		}
 	}
 
-	if ds.Consistency > cql.Any {
-		qw.args = append(qw.args, cql.WithConsistency(ds.Consistency))
-	}
+	qw.args = statementOptions{
+		consistency:       ds.Consistency,
+		serialConsistency: ds.SerialConsistency,
+		idempotent:        ds.Idempotent,
+		timeout:           ds.Timeout,
+	}.appendTo(qw.args)
 
 	return qw.String(), qw.args, nil
 }
@@ -52,7 +52,7 @@ type execer struct {
 }
 
 func (e *execer) Exec(ctx context.Context, qvs map[string]interface{}) error {
-	var stmt, vs, err = e.stmt.buildQuery(qvs)
+	var stmt, vs, err = e.stmt.buildQuery(unsetValues(e.db, e.stmt, qvs))
 
 	switch err {
 	case nil:
@@ -66,7 +66,7 @@ func (e *execer) Exec(ctx context.Context, qvs map[string]interface{}) error {
 }
 
 func (e *execer) ExecCAS(ctx context.Context, qvs map[string]interface{}) CASScanner {
-	var stmt, vs, err = e.stmt.buildQuery(qvs)
+	var stmt, vs, err = e.stmt.buildQuery(unsetValues(e.db, e.stmt, qvs))
 
 	switch err {
 	case nil:
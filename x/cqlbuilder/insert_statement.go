@@ -3,6 +3,7 @@ package cqlbuilder
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/upfluence/cql"
 )
@@ -18,9 +19,17 @@ type InsertStatement struct {
 
 	Fields []Marker
 
-	Options     DMLOptions
-	LWTClause   LWTInsertClause
-	Consistency cql.Consistency
+	// UnsetMissingFields binds cql.UnsetValue to the fields missing from the
+	// values so their columns are left untouched, it requires the native
+	// protocol v4 or later and ErrMissingKey is returned otherwise.
+	UnsetMissingFields bool
+
+	Options           DMLOptions
+	LWTClause         LWTInsertClause
+	Consistency       cql.Consistency
+	SerialConsistency cql.SerialConsistency
+	Idempotent        bool
+	Timeout           time.Duration
 }
 
 func (is InsertStatement) casScanKeys() []string {
@@ -33,6 +42,14 @@ func (is InsertStatement) casScanKeys() []string {
 	return ks
 }
 
+func (is InsertStatement) unsetKeys() []string {
+	if !is.UnsetMissingFields {
+		return nil
+	}
+
+	return is.casScanKeys()
+}
+
 func (is InsertStatement) buildQuery(qvs map[string]interface{}) (string, []interface{}, error) {
 	var (
 		qw queryWriter
@@ -76,9 +93,12 @@ func (is InsertStatement) buildQuery(qvs map[string]interface{}) (string, []inte
 
 	is.Options.writeTo(&qw)
 
-	if is.Consistency > cql.Any {
-		qw.args = append(qw.args, cql.WithConsistency(is.Consistency))
-	}
+	qw.args = statementOptions{
+		consistency:       is.Consistency,
+		serialConsistency: is.SerialConsistency,
+		idempotent:        is.Idempotent,
+		timeout:           is.Timeout,
+	}.appendTo(qw.args)
 
 	return qw.String(), qw.args, nil
 }
@@ -1,6 +1,14 @@
 package cqlbuilder
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/cql"
+	"github.com/upfluence/cql/cqltest"
+)
 
 func TestInsertStatement(t *testing.T) {
 	for _, stc := range []statementTestCase{
@@ -25,6 +33,23 @@ func TestInsertStatement(t *testing.T) {
 			wantStmt: "INSERT INTO foo(fiz, buz) VALUES (?, ?) IF NOT EXISTS",
 			wantArgs: []interface{}{1, 2},
 		},
+		{
+			name: "with serial consistency",
+			stmt: InsertStatement{
+				Table:             "foo",
+				Fields:            []Marker{Column("fiz")},
+				LWTClause:         NotExistsClause,
+				Consistency:       cql.LocalQuorum,
+				SerialConsistency: cql.LocalSerial,
+			},
+			vs:       map[string]interface{}{"fiz": 1},
+			wantStmt: "INSERT INTO foo(fiz) VALUES (?) IF NOT EXISTS",
+			wantArgs: []interface{}{
+				1,
+				cql.WithConsistency(cql.LocalQuorum),
+				cql.WithSerialConsistency(cql.LocalSerial),
+			},
+		},
 		{
 			name: "missing key",
 			stmt: InsertStatement{
@@ -38,3 +63,64 @@ func TestInsertStatement(t *testing.T) {
 		stc.assert(t)
 	}
 }
+
+type protoVersionDB struct {
+	cqltest.MockDB
+
+	version int
+}
+
+func (db *protoVersionDB) ProtoVersion() int { return db.version }
+
+func TestInsertUnsetValue(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		version int
+		unset   bool
+
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{
+			name:     "proto v4",
+			version:  4,
+			unset:    true,
+			wantArgs: []interface{}{1, cql.UnsetValue},
+		},
+		{
+			name:    "proto v3",
+			version: 3,
+			unset:   true,
+			wantErr: ErrMissingKey{Key: "buz"},
+		},
+		{
+			name:    "proto v4 without unset",
+			version: 4,
+			wantErr: ErrMissingKey{Key: "buz"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []interface{}
+
+			db := protoVersionDB{version: tt.version}
+			db.ExecFunc = func(_ context.Context, _ string, vs ...interface{}) error {
+				args = vs
+				return nil
+			}
+
+			qb := QueryBuilder{DB: &db}
+			ie := qb.PrepareInsert(
+				InsertStatement{
+					Table:              "foo",
+					Fields:             []Marker{Column("fiz"), Column("buz")},
+					UnsetMissingFields: tt.unset,
+				},
+			)
+
+			err := ie.Exec(context.Background(), map[string]interface{}{"fiz": 1})
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
@@ -6,6 +6,10 @@ type QueryBuilder struct {
 	cql.DB
 }
 
+func (qb *QueryBuilder) ProtoVersion() int {
+	return cql.ProtoVersion(qb.DB)
+}
+
 func (qb *QueryBuilder) PrepareInsert(is InsertStatement) *InsertExecer {
 	return &InsertExecer{
 		execer:       execer{stmt: is, db: qb.DB},
@@ -42,6 +46,40 @@ type statement interface {
 	buildQuery(map[string]interface{}) (string, []interface{}, error)
 }
 
+// unsetValues binds cql.UnsetValue to the unset keys of the statement missing
+// from qvs when the native protocol is v4 or later.
+func unsetValues(db cql.DB, stmt statement, qvs map[string]interface{}) map[string]interface{} {
+	us, ok := stmt.(interface{ unsetKeys() []string })
+
+	if !ok || cql.ProtoVersion(db) < 4 {
+		return qvs
+	}
+
+	var vs map[string]interface{}
+
+	for _, k := range us.unsetKeys() {
+		if _, ok := qvs[k]; ok {
+			continue
+		}
+
+		if vs == nil {
+			vs = make(map[string]interface{}, len(qvs)+1)
+
+			for k, v := range qvs {
+				vs[k] = v
+			}
+		}
+
+		vs[k] = cql.UnsetValue
+	}
+
+	if vs == nil {
+		return qvs
+	}
+
+	return vs
+}
+
 type CASStatement interface {
 	statement
 
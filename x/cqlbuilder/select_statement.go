@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/upfluence/cql"
 )
@@ -35,6 +36,8 @@ type SelectStatement struct {
 	Limit NullableInt
 
 	Consistency    cql.Consistency
+	Idempotent     bool
+	Timeout        time.Duration
 	AllowFiltering bool
 }
 
@@ -81,9 +84,11 @@ func (ss SelectStatement) buildQuery(qvs map[string]interface{}) (string, []inte
 		qw.WriteString(" ALLOW FILTERING")
 	}
 
-	if ss.Consistency > cql.Any {
-		qw.args = append(qw.args, cql.WithConsistency(ss.Consistency))
-	}
+	qw.args = statementOptions{
+		consistency: ss.Consistency,
+		idempotent:  ss.Idempotent,
+		timeout:     ss.Timeout,
+	}.appendTo(qw.args)
 
 	return qw.String(), qw.args, nil
 }
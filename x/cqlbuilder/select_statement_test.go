@@ -1,6 +1,11 @@
 package cqlbuilder
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/upfluence/cql"
+)
 
 func TestSelectStatement(t *testing.T) {
 	for _, stc := range []statementTestCase{
@@ -26,6 +31,25 @@ func TestSelectStatement(t *testing.T) {
 			},
 			wantStmt: "SELECT fiz, buz FROM foo WHERE 1 = 2 LIMIT 123",
 		},
+		{
+			name: "with options",
+			stmt: SelectStatement{
+				Table:         "foo",
+				SelectClauses: []Marker{Column("fiz")},
+				WhereClause:   Eq(Column("bar")),
+				Consistency:   cql.One,
+				Idempotent:    true,
+				Timeout:       time.Second,
+			},
+			vs:       map[string]interface{}{"bar": 3},
+			wantStmt: "SELECT fiz FROM foo WHERE bar = ?",
+			wantArgs: []interface{}{
+				3,
+				cql.WithConsistency(cql.One),
+				cql.Idempotent,
+				cql.WithTimeout(time.Second),
+			},
+		},
 		{
 			name: "basic and",
 			stmt: SelectStatement{
@@ -0,0 +1,44 @@
+package cqlbuilder
+
+import (
+	"time"
+
+	"github.com/upfluence/cql"
+)
+
+type statementOptions struct {
+	consistency       cql.Consistency
+	serialConsistency cql.SerialConsistency
+	idempotent        bool
+	timeout           time.Duration
+}
+
+func (so statementOptions) options() []cql.Option {
+	var opts []cql.Option
+
+	if so.consistency > cql.Any {
+		opts = append(opts, cql.WithConsistency(so.consistency))
+	}
+
+	if so.serialConsistency > 0 {
+		opts = append(opts, cql.WithSerialConsistency(so.serialConsistency))
+	}
+
+	if so.idempotent {
+		opts = append(opts, cql.Idempotent)
+	}
+
+	if so.timeout > 0 {
+		opts = append(opts, cql.WithTimeout(so.timeout))
+	}
+
+	return opts
+}
+
+func (so statementOptions) appendTo(vs []interface{}) []interface{} {
+	for _, opt := range so.options() {
+		vs = append(vs, opt)
+	}
+
+	return vs
+}
@@ -2,6 +2,7 @@ package cqlbuilder
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/upfluence/cql"
 	"github.com/upfluence/errors"
@@ -86,9 +87,12 @@ type UpdateStatement struct {
 	UpdateClauses []UpdateClause
 	WhereClause   PredicateClause
 
-	Options     DMLOptions
-	LWTClause   LWTUpdateClause
-	Consistency cql.Consistency
+	Options           DMLOptions
+	LWTClause         LWTUpdateClause
+	Consistency       cql.Consistency
+	SerialConsistency cql.SerialConsistency
+	Idempotent        bool
+	Timeout           time.Duration
 }
 
 func (us UpdateStatement) casScanKeys() []string {
@@ -134,9 +138,12 @@ func (us UpdateStatement) buildQuery(qvs map[string]interface{}) (string, []inte
 		}
 	}
 
-	if us.Consistency > cql.Any {
-		qw.args = append(qw.args, cql.WithConsistency(us.Consistency))
-	}
+	qw.args = statementOptions{
+		consistency:       us.Consistency,
+		serialConsistency: us.SerialConsistency,
+		idempotent:        us.Idempotent,
+		timeout:           us.Timeout,
+	}.appendTo(qw.args)
 
 	return qw.String(), qw.args, nil
 }